@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	input "github.com/prokls/cnf-analysis-go/input"
+)
+
+// dispatcher passes the files given as arguments to the workers.
+// Directories are walked recursively and file lists are read line by
+// line, such that jobs are created lazily while workers are running.
+type dispatcher struct {
+	proto        work
+	workDist     chan work
+	outputDir    string
+	stdinOutput  string
+	skipExisting bool
+	// glob patterns matched against base names of files found in directories
+	includes []string
+	excludes []string
+
+	stdinGiven  bool
+	collections []*collection
+	// real paths of directories walked already
+	visited map[string]bool
+}
+
+// validPatterns returns an error if a pattern is malformed
+func validPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		_, err := filepath.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("invalid pattern '%s': %s", pattern, err.Error())
+		}
+	}
+	return nil
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// accept tells whether a file with base name name found in a directory
+// is considered. Without include patterns, all files of a known input
+// format and archives are considered.
+func (d *dispatcher) accept(name string) bool {
+	if matchesAny(d.excludes, name) {
+		return false
+	}
+	if len(d.includes) > 0 {
+		return matchesAny(d.includes, name)
+	}
+	return isMemberFormat(name) || input.ArchiveFormat(name) != input.NoArchive
+}
+
+// claimStdin fails if stdin has been used before
+func (d *dispatcher) claimStdin() {
+	if d.stdinGiven {
+		fmt.Fprint(os.Stderr, "stdin can be read only once\n")
+		os.Exit(1)
+	}
+	d.stdinGiven = true
+}
+
+// dispatch handles an argument, which is "-" for stdin, "@filelist"
+// for a file listing paths, a directory or a file
+func (d *dispatcher) dispatch(arg string) {
+	if arg == "-" {
+		d.claimStdin()
+		job := d.proto
+		job.input = arg
+		job.output = d.stdinOutput
+		d.workDist <- job
+		return
+	}
+	if strings.HasPrefix(arg, "@") {
+		d.dispatchFileList(arg[1:])
+		return
+	}
+
+	info, err := os.Stat(arg)
+	if err == nil && info.IsDir() {
+		d.dispatchDir(arg)
+		return
+	}
+	d.dispatchFile(arg)
+}
+
+// dispatchFileList dispatches every path listed in the file at path,
+// one per line. "-" reads the list from stdin. Empty lines are skipped.
+func (d *dispatcher) dispatchFileList(path string) {
+	if path == "-" {
+		d.claimStdin()
+	}
+	fd, err := openInput(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read file list %s: %s\n", path, err.Error())
+		return
+	}
+	defer fd.Close()
+
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line == "-" || strings.HasPrefix(line, "@") {
+			// neither stdin nor nested lists are read from lists
+			if line != "" {
+				fmt.Fprintf(os.Stderr, "ignoring '%s' in file list %s\n", line, path)
+			}
+			continue
+		}
+		d.dispatch(line)
+	}
+	err = scanner.Err()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read file list %s: %s\n", path, err.Error())
+	}
+}
+
+// dispatchDir dispatches all accepted files in the directory at path
+// and its subdirectories in lexical order. Symbolic links are followed;
+// directories reached before (via symbolic link loops, for example)
+// are skipped.
+func (d *dispatcher) dispatchDir(path string) {
+	realPath, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		realPath, err = filepath.Abs(realPath)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read directory %s: %s\n", path, err.Error())
+		return
+	}
+	if d.visited[realPath] {
+		fmt.Fprintf(os.Stderr, "%s was visited previously - skipping\n", path)
+		return
+	}
+	d.visited[realPath] = true
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read directory %s: %s\n", path, err.Error())
+		// process the entries read nonetheless
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if matchesAny(d.excludes, name) {
+			continue
+		}
+		sub := filepath.Join(path, name)
+		mode := entry.Type()
+		if mode&os.ModeSymlink != 0 {
+			info, err := os.Stat(sub)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "could not follow symbolic link %s: %s\n", sub, err.Error())
+				continue
+			}
+			mode = info.Mode().Type()
+		}
+		if mode.IsDir() {
+			d.dispatchDir(sub)
+		} else if mode.IsRegular() && d.accept(name) {
+			d.dispatchFile(sub)
+		}
+	}
+}
+
+// dispatchFile dispatches the archive or file at path
+func (d *dispatcher) dispatchFile(path string) {
+	job := d.proto
+	job.input = path
+	if input.ArchiveFormat(path) != input.NoArchive {
+		coll, err := dispatchArchive(job, d.outputDir, d.skipExisting, d.workDist)
+		if err != nil {
+			report(job, "archive", fmt.Sprintf("could not read archive %s", path), err)
+		}
+		if coll != nil {
+			d.collections = append(d.collections, coll)
+		}
+		return
+	}
+
+	out, err := deriveFilePath(path, d.skipExisting)
+	if err != nil {
+		report(job, "io", fmt.Sprintf("could not move existing stats file of %s", path), err)
+		return
+	}
+	if out == "" {
+		fmt.Fprintf(os.Stderr, "%s was processed previously - skipping\n", path)
+		return
+	}
+	job.output = out
+	d.workDist <- job
+}
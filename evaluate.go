@@ -1,6 +1,9 @@
 package main
 
 import (
+	"math"
+
+	"github.com/prokls/cnf-analysis-go/input"
 	"github.com/prokls/cnf-analysis-go/output"
 	"github.com/prokls/cnf-analysis-go/sat"
 	"github.com/prokls/cnf-analysis-go/stats"
@@ -8,134 +11,122 @@ import (
 
 // the evaluated features are classified by their memory consumption
 
-func evaluateConstant(cnf *sat.CNF, feat *output.Features, fconf *stats.FeatureConfig) error {
-	// evaluating features: {ClausesCount, DefiniteClausesCount, GoalClausesCount,
-	//   FalseTrivial, LiteralsCount, NbClauses, NbVars, NegativeUnitClauseCount,
-	//   PositiveLiteralsCount, PositiveUnitClauseCount, TautologicalLiteralsCount,
-	//   TrueTrivial, TwoLiteralsClauseCount, VariablesLargest, VariablesSmallest}
-	var pos uint16
-	var neg uint16
-	var length uint16
-	var maxlength uint16
-
-	feat.NbClauses = uint32(cnf.NbClauses)
-	feat.NbVars = uint32(cnf.NbVars)
+func evaluateConstant[L sat.Literal](cnf *sat.Formula[L], feat *output.Features, fconf *stats.FeatureConfig) error {
+	// evaluating features: {ClausesCount, DefiniteClausesCount, EmptyClauseCount,
+	//   GoalClausesCount, FalseTrivial, LiteralsCount, NbClauses, NbVars,
+	//   NegativeUnitClauseCount, PositiveLiteralsCount, PositiveUnitClauseCount,
+	//   TautologicalLiteralsCount, TriviallyUnsat, TrueTrivial,
+	//   TwoLiteralsClauseCount, VariablesLargest, VariablesSmallest}
+	feat.NbClauses = uint64(cnf.NbClauses)
+	feat.NbVars = uint64(cnf.NbVars)
 	feat.TrueTrivial = true
 	feat.FalseTrivial = true
 	initSmallest := false
 
-	for _, lit := range cnf.Lits {
-		if lit == 0 {
-			if pos == 1 {
-				feat.DefiniteClausesCount += 1
-			} else if pos == 0 {
-				feat.GoalClausesCount += 1
-				feat.TrueTrivial = false
-			}
-			if neg == 0 {
-				feat.FalseTrivial = false
-			}
-			if length == 1 && pos > 0 {
-				feat.PositiveUnitClauseCount += 1
-			}
-			if length == 1 && neg > 0 {
-				feat.NegativeUnitClauseCount += 1
-			}
-			if length == 2 {
-				feat.TwoLiteralsClauseCount += 1
-			}
-			if length > maxlength {
-				maxlength = length
-			}
-			feat.ClausesCount += 1
-			length = 0
-			pos = 0
-			neg = 0
-		} else if lit > 0 {
-			pos += 1
-			length += 1
-			feat.LiteralsCount += 1
-			feat.PositiveLiteralsCount += 1
-			if uint32(lit) > feat.VariablesLargest {
-				feat.VariablesLargest = uint32(lit)
-			}
-			if !initSmallest || uint32(lit) < feat.VariablesSmallest {
-				feat.VariablesSmallest = uint32(lit)
-				initSmallest = true
+	for _, clause := range cnf.Clauses() {
+		var pos, neg int
+		for _, lit := range clause {
+			v := lit
+			if lit > 0 {
+				pos += 1
+				feat.PositiveLiteralsCount += 1
+			} else {
+				neg += 1
+				v = -lit
 			}
-		} else {
-			neg += 1
-			length += 1
 			feat.LiteralsCount += 1
-			if uint32(-lit) > feat.VariablesLargest {
-				feat.VariablesLargest = uint32(-lit)
+			if uint64(v) > feat.VariablesLargest {
+				feat.VariablesLargest = uint64(v)
 			}
-			if !initSmallest || uint32(-lit) < feat.VariablesSmallest {
-				feat.VariablesSmallest = uint32(-lit)
+			if !initSmallest || uint64(v) < feat.VariablesSmallest {
+				feat.VariablesSmallest = uint64(v)
 				initSmallest = true
 			}
 		}
+		if pos == 1 {
+			feat.DefiniteClausesCount += 1
+		} else if pos == 0 {
+			feat.GoalClausesCount += 1
+			feat.TrueTrivial = false
+		}
+		if neg == 0 {
+			feat.FalseTrivial = false
+		}
+		if len(clause) == 1 && pos > 0 {
+			feat.PositiveUnitClauseCount += 1
+		}
+		if len(clause) == 1 && neg > 0 {
+			feat.NegativeUnitClauseCount += 1
+		}
+		if len(clause) == 2 {
+			feat.TwoLiteralsClauseCount += 1
+		}
+		if len(clause) == 0 {
+			feat.EmptyClauseCount += 1
+			feat.TriviallyUnsat = true
+		}
+		feat.ClausesCount += 1
 	}
 
-	clause := make([]sat.Lit, maxlength)
-	ci := 0
-	skip := false
-
-	for _, lit := range cnf.Lits {
-		if skip && lit != 0 {
-			continue
-		}
-		if lit == 0 {
-			// reset
-			for i := uint16(0); i < maxlength; i++ {
-				clause[i] = 0
-			}
-			ci = 0
-			skip = false
-		} else {
-			// does clause contain -lit?
-			for i := 0; i < ci; i++ {
-				if clause[i] == -lit {
+	// tautological literals; the remaining literals of a clause are
+	// skipped after the first literal whose complement precedes it
+	for _, clause := range cnf.Clauses() {
+		for i, lit := range clause {
+			found := false
+			for _, prev := range clause[:i] {
+				if prev == -lit {
 					feat.TautologicalLiteralsCount += 1
-					skip = true
+					found = true
 				}
 			}
-			// store
-			clause[ci] = lit
-			ci += 1
+			if found {
+				break
+			}
 		}
 	}
 
 	return nil
 }
 
-func posEquiv(lit int32, nbvars int) int32 {
+func posEquiv(lit int, nbvars int) int {
 	if lit < 0 {
-		return int32(nbvars) + (-lit) - 1
+		return nbvars + (-lit) - 1
 	} else {
 		return lit - 1
 	}
 }
-func negEquiv(lit int32, nbvars int) int32 {
-	if lit >= int32(nbvars) {
-		return -(lit + 1 - int32(nbvars))
+func negEquiv(lit int, nbvars int) int {
+	if lit >= nbvars {
+		return -(lit + 1 - nbvars)
 	} else {
 		return lit + 1
 	}
 }
 
-func evaluateOccurence(cnf *sat.CNF, feat *output.Features, fconf *stats.FeatureConfig) error {
-	var err error
-	freq := make([]float32, 2*cnf.NbVars)
-	lowLit := int32(-cnf.NbVars)
-	lowVar := int32(1)
-	high := int32(cnf.NbVars)
+func evaluateOccurence[L sat.Literal](cnf *sat.Formula[L], feat *output.Features, fconf *stats.FeatureConfig) error {
+	nbvars := cnf.VarCount()
+	freq := make([]float32, 2*nbvars)
 
 	// retrieve occurence list
-	for _, lit := range cnf.Lits {
-		if lit != 0 {
-			freq[posEquiv(int32(lit), cnf.NbVars)] += 1
-		}
+	for lit := range cnf.Literals() {
+		freq[posEquiv(int(lit), nbvars)] += 1
+	}
+
+	return evaluateFrequencies(freq, nbvars, cnf.NbClauses, feat)
+}
+
+// evaluateFrequencies evaluates the literal and variable frequency
+// features given the number of occurences of each literal in freq
+// (indexed by posEquiv). freq is modified in-place.
+func evaluateFrequencies(freq []float32, nbvars, nbclauses int, feat *output.Features) error {
+	var err error
+	lowLit := -nbvars
+	lowVar := 1
+	high := nbvars
+
+	// no variables, no frequencies
+	if nbvars == 0 {
+		return nil
 	}
 
 	// determine existential literals
@@ -143,7 +134,7 @@ func evaluateOccurence(cnf *sat.CNF, feat *output.Features, fconf *stats.Feature
 		if lit == 0 {
 			continue
 		}
-		if freq[posEquiv(lit, cnf.NbVars)] == 1 && freq[posEquiv(-lit, cnf.NbVars)] == 0 {
+		if freq[posEquiv(lit, nbvars)] == 1 && freq[posEquiv(-lit, nbvars)] == 0 {
 			feat.ExistentialLiteralsCount += 1
 			if lit > 0 {
 				feat.ExistentialPositiveLiteralsCount += 1
@@ -156,29 +147,31 @@ func evaluateOccurence(cnf *sat.CNF, feat *output.Features, fconf *stats.Feature
 		if lit == 0 {
 			continue
 		}
-		if freq[posEquiv(lit, cnf.NbVars)] > 0.5 || freq[posEquiv(-lit, cnf.NbVars)] > 0.5 {
+		if freq[posEquiv(lit, nbvars)] > 0.5 || freq[posEquiv(-lit, nbvars)] > 0.5 {
 			feat.VariablesUsedCount += 1
 		}
 	}
 
 	// frequency = occurences / nbclauses
-	nbc32 := float32(cnf.NbClauses)
+	nbc32 := float32(nbclauses)
 	for lit := lowLit; lit <= high; lit++ {
 		if lit == 0 {
 			continue
 		}
-		index := posEquiv(lit, cnf.NbVars)
+		index := posEquiv(lit, nbvars)
 		if 0.5 < freq[index] && freq[index] < 1.5 {
 			feat.LiteralsOccurenceOneCount += 1
 		}
-		freq[index] /= nbc32
+		if nbclauses > 0 {
+			freq[index] /= nbc32
+		}
 		if freq[index] >= 1.0 {
 			freq[index] = 1.0
 		}
 	}
 
 	// write frequency
-	var freqFeats [20]*uint32 = [20]*uint32{
+	var freqFeats [20]*uint64 = [20]*uint64{
 		&feat.LiteralsFrequency0To5, &feat.LiteralsFrequency5To10,
 		&feat.LiteralsFrequency10To15, &feat.LiteralsFrequency15To20,
 		&feat.LiteralsFrequency20To25, &feat.LiteralsFrequency25To30,
@@ -194,7 +187,7 @@ func evaluateOccurence(cnf *sat.CNF, feat *output.Features, fconf *stats.Feature
 		if lit == 0 {
 			continue
 		}
-		class := int((100.0 * freq[posEquiv(lit, cnf.NbVars)]) / 5.0)
+		class := int((100.0 * freq[posEquiv(lit, nbvars)]) / 5.0)
 		if class == 20 {
 			class = 19
 		}
@@ -237,23 +230,23 @@ func evaluateOccurence(cnf *sat.CNF, feat *output.Features, fconf *stats.Feature
 		if lit == 0 {
 			continue
 		}
-		p := posEquiv(lit, cnf.NbVars)
-		n := posEquiv(-lit, cnf.NbVars)
+		p := posEquiv(lit, nbvars)
+		n := posEquiv(-lit, nbvars)
 		freq[p] = freq[p] + freq[n]
 		if freq[p] > 1.0 {
 			freq[p] = 1.0
 		}
-		if p > int32(end) {
-			end = int(p)
+		if p > end {
+			end = p
 		}
-		if !initStart || p < int32(start) {
-			start = int(p)
+		if !initStart || p < start {
+			start = p
 			initStart = true
 		}
 	}
 
 	// write frequency
-	freqFeats = [20]*uint32{
+	freqFeats = [20]*uint64{
 		&feat.VariablesFrequency0To5, &feat.VariablesFrequency5To10,
 		&feat.VariablesFrequency10To15, &feat.VariablesFrequency15To20,
 		&feat.VariablesFrequency20To25, &feat.VariablesFrequency25To30,
@@ -269,7 +262,7 @@ func evaluateOccurence(cnf *sat.CNF, feat *output.Features, fconf *stats.Feature
 		if lit == 0 {
 			continue
 		}
-		class := int(20.0 * freq[posEquiv(int32(lit), cnf.NbVars)])
+		class := int(20.0 * freq[posEquiv(lit, nbvars)])
 		if class == 20.0 {
 			class = 19
 		}
@@ -308,29 +301,38 @@ func evaluateOccurence(cnf *sat.CNF, feat *output.Features, fconf *stats.Feature
 	return nil
 }
 
-func evaluateVarSdPosNeg(cnf *sat.CNF, feat *output.Features, fconf *stats.FeatureConfig) error {
+func evaluateVarSdPosNeg[L sat.Literal](cnf *sat.Formula[L], feat *output.Features, fconf *stats.FeatureConfig) error {
 	var err error
-	clause := make([]uint32, 0, 64)
+	vars := make([]uint64, 0, 64)
 
 	// standard deviation of each clause, mean in CNF
+	// (the standard deviation of an empty clause is 0)
 	data := make([]float32, 0, cnf.NbClauses)
-	for _, lit := range cnf.Lits {
-		if lit > 0 {
-			clause = append(clause, uint32(lit))
-		} else if lit < 0 {
-			clause = append(clause, uint32(-lit))
-		} else {
-			mean, err := stats.MeanUint32(clause)
-			if err != nil {
-				return err
-			}
-			sd, err := stats.StdevUint32(clause, mean)
-			if err != nil {
-				return err
+	for _, clause := range cnf.Clauses() {
+		if len(clause) == 0 {
+			data = append(data, 0.0)
+			continue
+		}
+		vars = vars[:0]
+		for _, lit := range clause {
+			if lit > 0 {
+				vars = append(vars, uint64(lit))
+			} else {
+				vars = append(vars, uint64(-lit))
 			}
-			clause = clause[:0]
-			data = append(data, float32(sd))
 		}
+		mean, err := stats.MeanUint64(vars)
+		if err != nil {
+			return err
+		}
+		sd, err := stats.StdevUint64(vars, mean)
+		if err != nil {
+			return err
+		}
+		data = append(data, float32(sd))
+	}
+	if len(data) == 0 {
+		return nil
 	}
 	feat.ClauseVariablesSdMean, err = stats.MeanFloat32(data)
 	if err != nil {
@@ -338,29 +340,30 @@ func evaluateVarSdPosNeg(cnf *sat.CNF, feat *output.Features, fconf *stats.Featu
 	}
 
 	// ratio of positive/negative literals per clause
-	var pos, neg, i int
-	for _, lit := range cnf.Lits {
-		if lit == 0 {
-			ratio := float64(pos) / float64(pos+neg)
-			data[i] = float32(ratio)
-			i += 1
-			pos, neg = 0, 0
-		} else if lit > 0 {
-			pos += 1
-		} else if lit < 0 {
-			neg += 1
+	// (the ratio of an empty clause is 0)
+	for i, clause := range cnf.Clauses() {
+		pos := 0
+		for _, lit := range clause {
+			if lit > 0 {
+				pos += 1
+			}
+		}
+		ratio := 0.0
+		if len(clause) > 0 {
+			ratio = float64(pos) / float64(len(clause))
 		}
+		data[i] = float32(ratio)
 	}
-	mean, err := stats.MeanFloat32(data[:i])
+	mean, err := stats.MeanFloat32(data)
 	if err != nil {
 		return err
 	}
-	feat.PositiveNegativeLiteralsInClauseRatioEntropy, err = stats.EntropyFloat32(data[:i])
+	feat.PositiveNegativeLiteralsInClauseRatioEntropy, err = stats.EntropyFloat32(data)
 	if err != nil {
 		return err
 	}
 	feat.PositiveNegativeLiteralsInClauseRatioMean = mean
-	feat.PositiveNegativeLiteralsInClauseRatioStdev, err = stats.StdevFloat32(data[:i], mean)
+	feat.PositiveNegativeLiteralsInClauseRatioStdev, err = stats.StdevFloat32(data, mean)
 	if err != nil {
 		return err
 	}
@@ -368,105 +371,106 @@ func evaluateVarSdPosNeg(cnf *sat.CNF, feat *output.Features, fconf *stats.Featu
 	return nil
 }
 
-func evaluateClauseLengthPosNeg(cnf *sat.CNF, feat *output.Features, fconf *stats.FeatureConfig) error {
+// clauseSize converts the number of literals n of a clause to the
+// per-clause data of evaluateClauseLengthPosNeg. It saturates at
+// math.MaxUint32.
+func clauseSize(n int) uint32 {
+	if uint64(n) > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(n)
+}
+
+func evaluateClauseLengthPosNeg[L sat.Literal](cnf *sat.Formula[L], feat *output.Features, fconf *stats.FeatureConfig) error {
 	var err error
-	data := make([]uint16, cnf.NbClauses)
-	i := 0
+	data := make([]uint32, 0, cnf.NbClauses)
 
 	// determine length
-	var length uint16
-	for _, lit := range cnf.Lits {
-		if lit == 0 {
-			data[i] = length
-			i += 1
-			length = 0
-		} else {
-			length += 1
-		}
+	for _, clause := range cnf.Clauses() {
+		data = append(data, clauseSize(len(clause)))
+	}
+	if len(data) == 0 {
+		return nil
 	}
 
 	// store length features
-	feat.ClausesLengthLargest, err = stats.LargestUint16(data)
+	feat.ClausesLengthLargest, err = stats.LargestUint32(data)
 	if err != nil {
 		return err
 	}
-	feat.ClausesLengthMean, err = stats.MeanUint16(data)
+	feat.ClausesLengthMean, err = stats.MeanUint32(data)
 	if err != nil {
 		return err
 	}
-	feat.ClausesLengthMedian, err = stats.MedianUint16(data)
+	feat.ClausesLengthMedian, err = stats.MedianUint32(data)
 	if err != nil {
 		return err
 	}
-	feat.ClausesLengthSd, err = stats.StdevUint16(data, feat.ClausesLengthMean)
+	feat.ClausesLengthSd, err = stats.StdevUint32(data, feat.ClausesLengthMean)
 	if err != nil {
 		return err
 	}
-	feat.ClausesLengthSmallest, err = stats.SmallestUint16(data)
+	feat.ClausesLengthSmallest, err = stats.SmallestUint32(data)
 	if err != nil {
 		return err
 	}
 
 	// determine neg literals
-	var neg uint16
-	i = 0
-	for _, lit := range cnf.Lits {
-		if lit == 0 {
-			data[i] = neg
-			i += 1
-			neg = 0
-		} else if lit < 0 {
-			neg += 1
+	for i, clause := range cnf.Clauses() {
+		neg := 0
+		for _, lit := range clause {
+			if lit < 0 {
+				neg += 1
+			}
 		}
+		data[i] = clauseSize(neg)
 	}
 
 	// store neg-lits features
-	feat.NegativeLiteralsInClauseLargest, err = stats.LargestUint16(data)
+	feat.NegativeLiteralsInClauseLargest, err = stats.LargestUint32(data)
 	if err != nil {
 		return err
 	}
-	feat.NegativeLiteralsInClauseMean, err = stats.MeanUint16(data)
+	feat.NegativeLiteralsInClauseMean, err = stats.MeanUint32(data)
 	if err != nil {
 		return err
 	}
-	feat.NegativeLiteralsInClauseSmallest, err = stats.SmallestUint16(data)
+	feat.NegativeLiteralsInClauseSmallest, err = stats.SmallestUint32(data)
 	if err != nil {
 		return err
 	}
 
 	// determine pos literals
-	var pos uint16
-	i = 0
-	for _, lit := range cnf.Lits {
-		if lit == 0 {
-			data[i] = pos
-			i += 1
-			pos = 0
-		} else if lit > 0 {
-			pos += 1
+	for i, clause := range cnf.Clauses() {
+		pos := 0
+		for _, lit := range clause {
+			if lit > 0 {
+				pos += 1
+			}
 		}
+		data[i] = clauseSize(pos)
 	}
 
 	// store pos-lits features
-	mean, err := stats.MeanUint16(data)
+	mean, err := stats.MeanUint32(data)
 	if err != nil {
 		return err
 	}
-	feat.PositiveLiteralsInClauseLargest, err = stats.LargestUint16(data)
+	feat.PositiveLiteralsInClauseLargest, err = stats.LargestUint32(data)
 	if err != nil {
 		return err
 	}
 	feat.PositiveLiteralsInClauseMean = mean
-	med, err := stats.MedianUint16(data)
+	med, err := stats.MedianUint32(data)
 	if err != nil {
 		return err
 	}
 	feat.PositiveLiteralsInClauseMedian = float32(med)
-	feat.PositiveLiteralsInClauseSd, err = stats.StdevUint16(data, mean)
+	feat.PositiveLiteralsInClauseSd, err = stats.StdevUint32(data, mean)
 	if err != nil {
 		return err
 	}
-	feat.PositiveLiteralsInClauseSmallest, err = stats.SmallestUint16(data)
+	feat.PositiveLiteralsInClauseSmallest, err = stats.SmallestUint32(data)
 	if err != nil {
 		return err
 	}
@@ -474,7 +478,54 @@ func evaluateClauseLengthPosNeg(cnf *sat.CNF, feat *output.Features, fconf *stat
 	return nil
 }
 
-func evaluate(cnf *sat.CNF, feat *output.Features, fconf *stats.FeatureConfig) error {
+// evaluateHeader compares the header values with the actual values
+// found by the parser. occurs tells which variables occur in clauses,
+// variables of xors are considered used as well.
+func evaluateHeader[L sat.Literal](report *input.Report, occurs []bool, xors []L, feat *output.Features) error {
+	// evaluating features: {ActualClausesCount, HeaderNbClausesMismatch,
+	//   HeaderNbVarsMismatch, MaxVariable, UnusedDeclaredVariables}
+	feat.ActualClausesCount = uint64(report.ActualClauses)
+	feat.MaxVariable = uint64(report.ActualVars)
+	if report.DeclaredClauses >= 0 {
+		feat.HeaderNbClausesMismatch = report.DeclaredClauses != report.ActualClauses
+	}
+	if report.DeclaredVars < 0 {
+		return nil
+	}
+	feat.HeaderNbVarsMismatch = report.DeclaredVars != report.ActualVars
+
+	inXOR := occuringVariables(xors)
+	used := 0
+	for v := 1; v <= report.DeclaredVars && (v < len(occurs) || v < len(inXOR)); v++ {
+		if (v < len(occurs) && occurs[v]) || (v < len(inXOR) && inXOR[v]) {
+			used += 1
+		}
+	}
+	feat.UnusedDeclaredVariables = uint64(report.DeclaredVars - used)
+	return nil
+}
+
+// evaluateActual sets the actual values of evaluateHeader for formulas
+// without a header of their own, like simplified formulas and the
+// partitions of a WCNF. The features of cnf must be evaluated already.
+func evaluateActual[L sat.Literal](cnf *sat.Formula[L], feat *output.Features) {
+	// evaluating features: {ActualClausesCount, MaxVariable}
+	feat.ActualClausesCount = feat.ClausesCount
+	feat.MaxVariable = feat.VariablesLargest
+	for xor := range cnf.XORConstraints() {
+		feat.ActualClausesCount += 1
+		for _, lit := range xor {
+			if lit < 0 {
+				lit = -lit
+			}
+			if uint64(lit) > feat.MaxVariable {
+				feat.MaxVariable = uint64(lit)
+			}
+		}
+	}
+}
+
+func evaluate[L sat.Literal](cnf *sat.Formula[L], feat *output.Features, fconf *stats.FeatureConfig) error {
 	var err error
 
 	err = evaluateConstant(cnf, feat, fconf)
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/stats"
+)
+
+func evaluateAssumptions(inc *sat.Increment, feat *output.IncrementFeatures) error {
+	// evaluating features: {AssumptionsCount, NegativeAssumptionsCount,
+	//   PositiveAssumptionsCount, PositiveAssumptionsFraction}
+	feat.AssumptionsCount = uint64(len(inc.Assumptions))
+	for _, lit := range inc.Assumptions {
+		if lit.Pos() {
+			feat.PositiveAssumptionsCount += 1
+		} else {
+			feat.NegativeAssumptionsCount += 1
+		}
+	}
+	if feat.AssumptionsCount > 0 {
+		feat.PositiveAssumptionsFraction = float64(feat.PositiveAssumptionsCount) / float64(feat.AssumptionsCount)
+	}
+	return nil
+}
+
+// evaluateICNF evaluates the features of all clauses of an incremental
+// CNF and, for every increment, of the clauses given until its call.
+// The features of the increments are accumulated by a streamEvaluator
+// with exact medians, hence every clause is considered once.
+func evaluateICNF(icnf *sat.ICNF, stat *output.Stats, fconf *stats.FeatureConfig) error {
+	err := evaluate(icnf.CNF, &stat.Fts, fconf)
+	if err != nil {
+		return err
+	}
+
+	acc := newStreamEvaluator(output.NewFeatures())
+	acc.lengthMedian = new(stats.IntMedian)
+	acc.posLiteralsMedian = new(stats.IntMedian)
+
+	stat.Increments = make([]*output.IncrementFeatures, 0, len(icnf.Increments))
+	previous := 0
+	previousLits := 0
+	for i := range icnf.Increments {
+		inc := &icnf.Increments[i]
+		feat := output.NewIncrementFeatures()
+		feat.AddedClausesCount = uint64(inc.NbClauses - previous)
+		previous = inc.NbClauses
+
+		err = evaluateAssumptions(inc, feat)
+		if err != nil {
+			return err
+		}
+		for _, clause := range sat.SplitClauses(icnf.CNF.Lits[previousLits:inc.NbLits]) {
+			err = acc.Clause(clause)
+			if err != nil {
+				return err
+			}
+		}
+		previousLits = inc.NbLits
+		prefix := icnf.Prefix(i)
+		fts, err := acc.Snapshot(prefix)
+		if err != nil {
+			return err
+		}
+		fts.ActualClausesCount = uint64(prefix.NbClauses)
+		fts.MaxVariable = uint64(prefix.NbVars)
+		feat.Fts = *fts
+		stat.Increments = append(stat.Increments, feat)
+	}
+	return nil
+}
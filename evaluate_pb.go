@@ -0,0 +1,179 @@
+package main
+
+import (
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/stats"
+)
+
+// classes of normalized pseudo-Boolean constraints ordered by generality
+const (
+	trivialConstraint = iota
+	clausalConstraint
+	cardinalityConstraint
+	generalConstraint
+)
+
+// classifyConstraint determines the class of a normalized constraint.
+// Coefficients exceeding the degree are saturated to the degree first.
+// Then a constraint is cardinal if all coefficients are equal and
+// clausal if they additionally equal the degree.
+func classifyConstraint(c *sat.PBConstraint) int {
+	if c.Degree <= 0 {
+		return trivialConstraint
+	}
+	var first int64
+	for _, t := range c.Terms {
+		coef := t.Coef
+		if coef > c.Degree {
+			coef = c.Degree
+		}
+		if coef == 0 {
+			continue
+		}
+		if first == 0 {
+			first = coef
+		} else if coef != first {
+			return generalConstraint
+		}
+	}
+	if first == 0 || first == c.Degree {
+		return clausalConstraint
+	}
+	return cardinalityConstraint
+}
+
+func evaluatePBConstraints(pbf *sat.PBF, feat *output.PBFeatures, clauses *sat.CNF) error {
+	// evaluating features: {*ConstraintsCount, Coefficients*,
+	//   ConstraintsLength*, Degree*, NbConstraints, NbVars,
+	//   ObjectiveTermsCount}
+	feat.NbConstraints = uint64(pbf.NbConstraints)
+	feat.NbVars = uint64(pbf.NbVars)
+	feat.ObjectiveTermsCount = uint64(len(pbf.Objective))
+	feat.ConstraintsCount = uint64(len(pbf.Constraints))
+	if len(pbf.Constraints) == 0 {
+		return nil
+	}
+
+	var lengths, coefs, degrees stats.Welford
+	occurences := make(map[int64]int)
+	for i := range pbf.Constraints {
+		c := &pbf.Constraints[i]
+		switch c.Relation {
+		case sat.GreaterEqual:
+			feat.GreaterEqualConstraintsCount += 1
+		case sat.Equal:
+			feat.EqualityConstraintsCount += 1
+		case sat.LessEqual:
+			feat.LessEqualConstraintsCount += 1
+		}
+		lengths.Add(float64(len(c.Terms)))
+
+		// an equality is as general as the more general of its halves
+		class := trivialConstraint
+		normalized, err := c.Normalize()
+		if err != nil {
+			return err
+		}
+		for j := range normalized {
+			n := &normalized[j]
+			if cl := classifyConstraint(n); cl > class {
+				class = cl
+			}
+			degrees.Add(float64(n.Degree))
+			for _, t := range n.Terms {
+				coefs.Add(float64(t.Coef))
+				occurences[t.Coef] += 1
+			}
+		}
+
+		switch class {
+		case trivialConstraint:
+			feat.TrivialConstraintsCount += 1
+		case clausalConstraint:
+			feat.ClausalConstraintsCount += 1
+			for j := range normalized {
+				if classifyConstraint(&normalized[j]) != clausalConstraint {
+					continue
+				}
+				for _, t := range normalized[j].Terms {
+					if t.Coef != 0 {
+						clauses.Lits = append(clauses.Lits, t.Lit)
+					}
+				}
+				clauses.Lits = append(clauses.Lits, 0)
+				clauses.NbClauses += 1
+			}
+		case cardinalityConstraint:
+			feat.CardinalityConstraintsCount += 1
+		case generalConstraint:
+			feat.GeneralConstraintsCount += 1
+		}
+	}
+
+	feat.ConstraintsLengthLargest = uint64(lengths.Largest())
+	feat.ConstraintsLengthMean = lengths.Mean()
+	feat.ConstraintsLengthSd = lengths.Stdev()
+	feat.ConstraintsLengthSmallest = uint64(lengths.Smallest())
+
+	feat.DegreeLargest = int64(degrees.Largest())
+	feat.DegreeMean = degrees.Mean()
+	feat.DegreeSd = degrees.Stdev()
+	feat.DegreeSmallest = int64(degrees.Smallest())
+
+	if coefs.Count() == 0 {
+		return nil
+	}
+	feat.CoefficientsLargest = int64(coefs.Largest())
+	feat.CoefficientsMean = coefs.Mean()
+	feat.CoefficientsSd = coefs.Stdev()
+	feat.CoefficientsSmallest = int64(coefs.Smallest())
+
+	// entropy of the distribution of coefficient values
+	probs := make([]float64, 0, len(occurences))
+	for _, count := range occurences {
+		probs = append(probs, float64(count)/float64(coefs.Count()))
+	}
+	entropy, err := stats.EntropyFloat64(probs)
+	if err != nil {
+		return err
+	}
+	feat.CoefficientsEntropy = entropy
+	feat.CoefficientsDistinctCount = uint64(len(occurences))
+
+	return nil
+}
+
+// pbVariables returns whether variable v occurs in the objective or
+// a constraint of pbf at index v, see occuringVariables
+func pbVariables(pbf *sat.PBF) []bool {
+	lits := make([]sat.Lit, 0, len(pbf.Objective))
+	for _, t := range pbf.Objective {
+		lits = append(lits, t.Lit)
+	}
+	for _, c := range pbf.Constraints {
+		for _, t := range c.Terms {
+			lits = append(lits, t.Lit)
+		}
+	}
+	return occuringVariables(lits)
+}
+
+// evaluatePB evaluates the constraints of pbf into FtsPB and
+// its clausal constraints as CNF into Fts
+func evaluatePB(pbf *sat.PBF, stat *output.Stats, fconf *stats.FeatureConfig) error {
+	stat.FtsPB = output.NewPBFeatures()
+	clauses := sat.NewCNF()
+	clauses.NbVars = pbf.NbVars
+
+	err := evaluatePBConstraints(pbf, stat.FtsPB, clauses)
+	if err != nil {
+		return err
+	}
+	err = stats.EvaluatePBComponents(pbf, stat.FtsPB)
+	if err != nil {
+		return err
+	}
+
+	return evaluate(clauses, &stat.Fts, fconf)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/solver"
+)
+
+// evaluateProbing makes random DPLL probes on cnf and stores the unit
+// propagations per depth and the search space estimate in stat
+func evaluateProbing[L sat.Literal](cnf *sat.Formula[L], stat *output.Stats, pconf *solver.ProbeConfig) {
+	r := solver.Probe(cnf, pconf)
+
+	p := output.NewProbingFeatures()
+	for i, depth := range r.Depths {
+		units := uint64(r.UnitPropagations[i])
+		switch depth {
+		case 1:
+			p.UnitPropagationsDepth1 = units
+		case 4:
+			p.UnitPropagationsDepth4 = units
+		case 16:
+			p.UnitPropagationsDepth16 = units
+		case 64:
+			p.UnitPropagationsDepth64 = units
+		case 256:
+			p.UnitPropagationsDepth256 = units
+		}
+	}
+	p.ProbeDepthMean = r.ProbeDepthMean
+	p.ProbesCount = uint64(r.Probes)
+	p.SearchSpaceLog2 = r.SearchSpaceLog2
+	stat.Probing = p
+}
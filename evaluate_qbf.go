@@ -0,0 +1,138 @@
+package main
+
+import (
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/stats"
+)
+
+func evaluatePrefix(qbf *sat.QBF, feat *output.QBFFeatures) error {
+	// evaluating features: {AlternationsCount, BlocksCount, BlocksSize*,
+	//   ExistentialVariablesCount, FreeVariablesCount, Innermost*, Outermost*,
+	//   UniversalVariablesCount, UniversalVariablesFraction}
+	matrix := qbf.Matrix
+	feat.BlocksCount = uint64(len(qbf.Prefix))
+	if len(qbf.Prefix) == 0 {
+		return nil
+	}
+	feat.AlternationsCount = uint64(len(qbf.Prefix) - 1)
+
+	var sizes stats.Welford
+	for _, block := range qbf.Prefix {
+		sizes.Add(float64(len(block.Vars)))
+		if block.Quantifier == sat.Forall {
+			feat.UniversalVariablesCount += uint64(len(block.Vars))
+		} else {
+			feat.ExistentialVariablesCount += uint64(len(block.Vars))
+		}
+	}
+	feat.BlocksSizeLargest = uint64(sizes.Largest())
+	feat.BlocksSizeMean = sizes.Mean()
+	feat.BlocksSizeSd = sizes.Stdev()
+	feat.BlocksSizeSmallest = uint64(sizes.Smallest())
+	// variables exceeding the header, also of the prefix, are counted
+	if nbvars := matrix.VarCount(); nbvars > 0 {
+		feat.UniversalVariablesFraction = float64(feat.UniversalVariablesCount) / float64(nbvars)
+	}
+
+	outermost := qbf.Prefix[0]
+	innermost := qbf.Prefix[len(qbf.Prefix)-1]
+	feat.OutermostBlockQuantifier = string(outermost.Quantifier)
+	feat.OutermostBlockSize = uint64(len(outermost.Vars))
+	feat.InnermostBlockQuantifier = string(innermost.Quantifier)
+	feat.InnermostBlockSize = uint64(len(innermost.Vars))
+
+	return nil
+}
+
+func evaluateMatrixQuantifiers(qbf *sat.QBF, feat *output.QBFFeatures) error {
+	// evaluating features: {ClausesWithUniversalsCount, FreeVariablesCount,
+	//   InnermostBlockClausesCount, OutermostBlockClausesCount,
+	//   UniversalLiteralsInClauseMean}
+	matrix := qbf.Matrix
+
+	// block index of each variable, -1 for free variables
+	blockOf := make([]int32, matrix.VarCount()+1)
+	for i := range blockOf {
+		blockOf[i] = -1
+	}
+	for b, block := range qbf.Prefix {
+		for _, v := range block.Vars {
+			for int(v) >= len(blockOf) {
+				blockOf = append(blockOf, -1)
+			}
+			blockOf[v] = int32(b)
+		}
+	}
+	used := make([]bool, len(blockOf))
+	innermost := int32(len(qbf.Prefix) - 1)
+
+	var universals, clauses uint64
+	for _, clause := range matrix.Clauses() {
+		var hasUniversal, hasInnermost, hasOutermost bool
+		for _, lit := range clause {
+			v := lit
+			if v < 0 {
+				v = -v
+			}
+			for int(v) >= len(blockOf) {
+				blockOf = append(blockOf, -1)
+				used = append(used, false)
+			}
+			used[v] = true
+
+			b := blockOf[v]
+			if b < 0 {
+				continue
+			}
+			if qbf.Prefix[b].Quantifier == sat.Forall {
+				universals += 1
+				hasUniversal = true
+			}
+			if b == innermost {
+				hasInnermost = true
+			}
+			if b == 0 {
+				hasOutermost = true
+			}
+		}
+
+		if hasUniversal {
+			feat.ClausesWithUniversalsCount += 1
+		}
+		if hasInnermost {
+			feat.InnermostBlockClausesCount += 1
+		}
+		if hasOutermost {
+			feat.OutermostBlockClausesCount += 1
+		}
+		clauses += 1
+	}
+
+	for v := 1; v < len(used); v++ {
+		if used[v] && blockOf[v] < 0 {
+			feat.FreeVariablesCount += 1
+		}
+	}
+	if clauses > 0 {
+		feat.UniversalLiteralsInClauseMean = float64(universals) / float64(clauses)
+	}
+
+	return nil
+}
+
+// evaluateQBF evaluates the features of the matrix and the
+// quantifier prefix of a QBF
+func evaluateQBF(qbf *sat.QBF, stat *output.Stats, fconf *stats.FeatureConfig) error {
+	err := evaluate(qbf.Matrix, &stat.Fts, fconf)
+	if err != nil {
+		return err
+	}
+
+	stat.FtsQBF = output.NewQBFFeatures()
+	err = evaluatePrefix(qbf, stat.FtsQBF)
+	if err != nil {
+		return err
+	}
+	return evaluateMatrixQuantifiers(qbf, stat.FtsQBF)
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestUniversalVariablesFraction(t *testing.T) {
+	tests := []struct {
+		content  string
+		fraction float64
+	}{
+		{"p cnf 4 1\na 1 2 3 0\ne 4 0\n1 4 0\n", 0.75},
+		// the prefix quantifies variables beyond the header
+		{"p cnf 2 1\na 1 2 3 0\ne 4 0\n1 4 0\n", 0.75},
+		{"p cnf 0 0\na 1 0\n", 1},
+		{"p cnf 0 0\n", 0},
+	}
+	for _, test := range tests {
+		stat := analyzeString(t, work{input: "f.qdimacs"}, test.content)
+		if got := stat.FtsQBF.UniversalVariablesFraction; got != test.fraction {
+			t.Errorf("%q: universal variables fraction %f, expected %f", test.content, got, test.fraction)
+		}
+	}
+}
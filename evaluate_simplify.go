@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/simplify"
+	"github.com/prokls/cnf-analysis-go/stats"
+)
+
+// evaluateSimplified simplifies cnf and evaluates the features of the
+// simplified formula and what the simplification removed
+func evaluateSimplified[L sat.Literal](cnf *sat.Formula[L], stat *output.Stats, fconf *stats.FeatureConfig) error {
+	simplified, report := simplify.Simplify(cnf)
+
+	s := output.NewSimplificationFeatures()
+	s.Conflict = report.Conflict
+	s.DuplicateLiteralsRemoved = uint64(report.DuplicateLiterals)
+	s.FalsifiedLiteralsRemoved = uint64(report.FalsifiedLiterals)
+	s.PureClausesRemoved = uint64(report.PureClauses)
+	s.PureLiteralsAssigned = uint64(report.PureLiterals)
+	s.SatisfiedClausesRemoved = uint64(report.SatisfiedClauses)
+	s.SubsumedClausesRemoved = uint64(report.SubsumedClauses)
+	s.TautologiesRemoved = uint64(report.Tautologies)
+	s.UnitsAssigned = uint64(report.UnitVariables)
+	stat.Simplification = s
+
+	stat.FtsSimplified = output.NewFeatures()
+	err := evaluate(simplified, stat.FtsSimplified, fconf)
+	if err != nil {
+		return err
+	}
+	evaluateActual(simplified, stat.FtsSimplified)
+	return nil
+}
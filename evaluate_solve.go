@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/solver"
+)
+
+// evaluateSolving runs the CDCL solver on cnf within budget and
+// stores its answer and effort in stat
+func evaluateSolving[L sat.Literal](cnf *sat.Formula[L], stat *output.Stats, budget *solver.Budget) {
+	r := solver.Solve(cnf, budget)
+
+	s := output.NewSolvingFeatures()
+	s.SatStatus = r.Status.String()
+	s.ConflictsCount = uint64(r.Conflicts)
+	s.DecisionsCount = uint64(r.Decisions)
+	s.PropagationsCount = uint64(r.Propagations)
+	s.RestartsCount = uint64(r.Restarts)
+	s.DeletedClausesCount = uint64(r.DeletedClauses)
+	s.LearntClausesCount = uint64(r.LearntClauses)
+	s.LearntUnitClausesCount = uint64(r.LearntUnits)
+	s.LearntClausesLengthLargest = uint64(r.LearntLengthLargest)
+	if r.LearntClauses > 0 {
+		s.LearntClausesLengthMean = float64(r.LearntLiterals) / float64(r.LearntClauses)
+		s.LearntClausesLBDMean = float64(r.LearntLBD) / float64(r.LearntClauses)
+	}
+	s.SolvingTime = r.Time.Seconds()
+	stat.Solving = s
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"math"
+
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/stats"
+)
+
+// approximateFeatures lists the features which streamEvaluator
+// cannot determine exactly and therefore estimates
+var approximateFeatures = []string{
+	"clauses_length_median",
+	"positive_literals_in_clause_median",
+}
+
+// streamEvaluator evaluates features of a CNF whose clauses are passed
+// one at a time by input.StreamCNFFile. Its memory consumption is
+// linear in the number of variables and independent of the number of
+// literals. All features except for approximateFeatures are exact up
+// to floating-point rounding: means and standard deviations are
+// accumulated online, whereas evaluate sums buckets in float32.
+type streamEvaluator struct {
+	feat         *output.Features
+	initSmallest bool
+
+	// occurences of variable v as positive/negative literal at index v-1
+	posOcc []uint32
+	negOcc []uint32
+
+	components *stats.ComponentCounter
+	vars       []uint32
+
+	clauseVariablesSd stats.Welford
+	ratio             stats.Welford
+	ratioEntropy      float64
+	length            stats.Welford
+	lengthMedian      quantile
+	negLiterals       stats.Welford
+	posLiterals       stats.Welford
+	posLiteralsMedian quantile
+}
+
+// quantile is implemented by stats.P2Quantile and stats.IntMedian
+type quantile interface {
+	Add(x float64)
+	Value() float64
+}
+
+func newStreamEvaluator(feat *output.Features) *streamEvaluator {
+	e := new(streamEvaluator)
+	e.feat = feat
+	e.feat.TrueTrivial = true
+	e.feat.FalseTrivial = true
+	e.components = stats.NewComponentCounter(0)
+	e.vars = make([]uint32, 0, 64)
+	e.lengthMedian = stats.NewP2Quantile(0.5)
+	e.posLiteralsMedian = stats.NewP2Quantile(0.5)
+	return e
+}
+
+// grow ensures occurence lists exist for variables up to v
+func (e *streamEvaluator) grow(v int) {
+	for len(e.posOcc) < v {
+		e.posOcc = append(e.posOcc, 0)
+		e.negOcc = append(e.negOcc, 0)
+	}
+}
+
+// occuringVariables returns whether variable v occured in a clause
+// at index v
+func (e *streamEvaluator) occuringVariables() []bool {
+	occurs := make([]bool, len(e.posOcc)+1)
+	for i := range e.posOcc {
+		occurs[i+1] = e.posOcc[i] > 0 || e.negOcc[i] > 0
+	}
+	return occurs
+}
+
+// Clause considers one clause and implements input.ClauseHandler
+func (e *streamEvaluator) Clause(clause []sat.Lit) error {
+	feat := e.feat
+	var pos, neg int
+
+	e.vars = e.vars[:0]
+	for _, lit := range clause {
+		v := lit
+		if v < 0 {
+			v = -v
+		}
+		e.grow(int(v))
+		if lit > 0 {
+			pos += 1
+			e.posOcc[v-1] += 1
+			feat.PositiveLiteralsCount += 1
+		} else {
+			neg += 1
+			e.negOcc[v-1] += 1
+		}
+		feat.LiteralsCount += 1
+		if uint64(v) > feat.VariablesLargest {
+			feat.VariablesLargest = uint64(v)
+		}
+		if !e.initSmallest || uint64(v) < feat.VariablesSmallest {
+			feat.VariablesSmallest = uint64(v)
+			e.initSmallest = true
+		}
+		e.vars = append(e.vars, uint32(v))
+	}
+	length := len(clause)
+
+	// constant features, see evaluateConstant
+	if pos == 1 {
+		feat.DefiniteClausesCount += 1
+	} else if pos == 0 {
+		feat.GoalClausesCount += 1
+		feat.TrueTrivial = false
+	}
+	if neg == 0 {
+		feat.FalseTrivial = false
+	}
+	if length == 1 && pos > 0 {
+		feat.PositiveUnitClauseCount += 1
+	}
+	if length == 1 && neg > 0 {
+		feat.NegativeUnitClauseCount += 1
+	}
+	if length == 2 {
+		feat.TwoLiteralsClauseCount += 1
+	}
+	if length == 0 {
+		feat.EmptyClauseCount += 1
+		feat.TriviallyUnsat = true
+	}
+	feat.ClausesCount += 1
+
+	for i, lit := range clause {
+		tautological := false
+		for _, prev := range clause[:i] {
+			if prev == -lit {
+				feat.TautologicalLiteralsCount += 1
+				tautological = true
+			}
+		}
+		if tautological {
+			break
+		}
+	}
+
+	// standard deviation of variables and pos/neg ratio, see evaluateVarSdPosNeg
+	var sd, ratio float64
+	if length > 0 {
+		mean, err := stats.MeanUint32(e.vars)
+		if err != nil {
+			return err
+		}
+		sd, err = stats.StdevUint32(e.vars, mean)
+		if err != nil {
+			return err
+		}
+		ratio = float64(float32(float64(pos) / float64(length)))
+	}
+	e.clauseVariablesSd.Add(float64(float32(sd)))
+	e.ratio.Add(ratio)
+	if ratio > 0.0 {
+		e.ratioEntropy -= ratio * math.Log2(ratio)
+	}
+
+	// clause lengths, see evaluateClauseLengthPosNeg
+	e.length.Add(float64(clauseSize(length)))
+	e.lengthMedian.Add(float64(clauseSize(length)))
+	e.negLiterals.Add(float64(clauseSize(neg)))
+	e.posLiterals.Add(float64(clauseSize(pos)))
+	e.posLiteralsMedian.Add(float64(clauseSize(pos)))
+
+	return e.components.AddClause(clause)
+}
+
+// Finish evaluates the remaining features after the last clause.
+// cnf provides the header values.
+func (e *streamEvaluator) Finish(cnf *sat.CNF) error {
+	return e.finish(cnf, e.feat)
+}
+
+// Snapshot returns the features of the clauses considered so far;
+// more clauses may be considered afterwards. cnf provides the header
+// values.
+func (e *streamEvaluator) Snapshot(cnf *sat.CNF) (*output.Features, error) {
+	feat := new(output.Features)
+	*feat = *e.feat
+	return feat, e.finish(cnf, feat)
+}
+
+// finish stores the features evaluated from the accumulators in feat,
+// which holds the counters of e.feat
+func (e *streamEvaluator) finish(cnf *sat.CNF, feat *output.Features) error {
+	feat.NbClauses = uint64(cnf.NbClauses)
+	feat.NbVars = uint64(cnf.NbVars)
+
+	nbvars := cnf.VarCount()
+	if len(e.posOcc) > nbvars {
+		nbvars = len(e.posOcc)
+	}
+	e.grow(nbvars)
+
+	freq := make([]float32, 2*nbvars)
+	for v := 1; v <= nbvars; v++ {
+		freq[posEquiv(v, nbvars)] = float32(e.posOcc[v-1])
+		freq[posEquiv(-v, nbvars)] = float32(e.negOcc[v-1])
+	}
+	err := evaluateFrequencies(freq, nbvars, cnf.NbClauses, feat)
+	if err != nil {
+		return err
+	}
+
+	feat.ClauseVariablesSdMean = e.clauseVariablesSd.Mean()
+	feat.PositiveNegativeLiteralsInClauseRatioEntropy = e.ratioEntropy
+	feat.PositiveNegativeLiteralsInClauseRatioMean = e.ratio.Mean()
+	feat.PositiveNegativeLiteralsInClauseRatioStdev = e.ratio.Stdev()
+
+	feat.ClausesLengthLargest = uint32(e.length.Largest())
+	feat.ClausesLengthMean = e.length.Mean()
+	feat.ClausesLengthMedian = e.lengthMedian.Value()
+	feat.ClausesLengthSd = e.length.Stdev()
+	feat.ClausesLengthSmallest = uint32(e.length.Smallest())
+
+	feat.NegativeLiteralsInClauseLargest = uint32(e.negLiterals.Largest())
+	feat.NegativeLiteralsInClauseMean = e.negLiterals.Mean()
+	feat.NegativeLiteralsInClauseSmallest = uint32(e.negLiterals.Smallest())
+
+	feat.PositiveLiteralsInClauseLargest = uint32(e.posLiterals.Largest())
+	feat.PositiveLiteralsInClauseMean = e.posLiterals.Mean()
+	feat.PositiveLiteralsInClauseMedian = float32(e.posLiteralsMedian.Value())
+	feat.PositiveLiteralsInClauseSd = e.posLiterals.Stdev()
+	feat.PositiveLiteralsInClauseSmallest = uint32(e.posLiterals.Smallest())
+
+	return e.components.Evaluate(nbvars, feat)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prokls/cnf-analysis-go/output"
+)
+
+// randomDIMACS returns a DIMACS CNF with up to nbvars variables
+func randomDIMACS(rng *rand.Rand, nbvars int) string {
+	var b strings.Builder
+	nbclauses := rng.Intn(10 * nbvars)
+	fmt.Fprintf(&b, "p cnf %d %d\n", nbvars, nbclauses)
+	for c := 0; c < nbclauses; c++ {
+		for k := rng.Intn(6); k > 0; k-- {
+			lit := rng.Intn(nbvars) + 1
+			if rng.Intn(2) == 0 {
+				lit = -lit
+			}
+			fmt.Fprintf(&b, "%d ", lit)
+		}
+		b.WriteString("0\n")
+	}
+	return b.String()
+}
+
+// compareFeatures reports the features of got differing from want;
+// floating-point features may differ by rounding errors, which are
+// those of float32 as evaluate accumulates means in float32
+func compareFeatures(t *testing.T, got, want *output.Features, skip []string) {
+	t.Helper()
+	g := reflect.ValueOf(got).Elem()
+	w := reflect.ValueOf(want).Elem()
+	for i := 0; i < g.NumField(); i++ {
+		name := strings.Split(g.Type().Field(i).Tag.Get("json"), ",")[0]
+		if slices.Contains(skip, name) {
+			continue
+		}
+		switch a, b := g.Field(i), w.Field(i); a.Kind() {
+		case reflect.Float32, reflect.Float64:
+			if math.Abs(a.Float()-b.Float()) > 1e-5*math.Max(1, math.Abs(b.Float())) {
+				t.Errorf("%s is %v, expected %v", name, a.Float(), b.Float())
+			}
+		default:
+			if a.Interface() != b.Interface() {
+				t.Errorf("%s is %v, expected %v", name, a.Interface(), b.Interface())
+			}
+		}
+	}
+}
+
+func TestStreamEvaluator(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		content := randomDIMACS(rng, rng.Intn(50)+1)
+		stat := analyzeString(t, work{input: "f.cnf"}, content)
+		streamed := analyzeString(t, work{input: "f.cnf", stream: true}, content)
+
+		if !slices.Equal(streamed.Approximate, approximateFeatures) {
+			t.Errorf("approximate features %v", streamed.Approximate)
+		}
+		compareFeatures(t, &streamed.Fts, &stat.Fts, approximateFeatures)
+		if t.Failed() {
+			t.Fatalf("features of\n%s", content)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prokls/cnf-analysis-go/input"
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/stats"
+)
+
+func TestEvaluateLongClause(t *testing.T) {
+	const length = 1 << 16
+	cnf := sat.NewCNF()
+	for lit := sat.Lit(1); lit <= length; lit++ {
+		cnf.Lits = append(cnf.Lits, lit)
+	}
+	cnf.Lits = append(cnf.Lits, 0, -1, 0)
+	cnf.NbVars = length
+	cnf.NbClauses = 2
+
+	feat := output.NewFeatures()
+	fconf := stats.NewFeatureConfig()
+	if err := evaluateConstant(cnf, feat, fconf); err != nil {
+		t.Fatal(err)
+	}
+	if err := evaluateClauseLengthPosNeg(cnf, feat, fconf); err != nil {
+		t.Fatal(err)
+	}
+	if feat.EmptyClauseCount != 0 || feat.TriviallyUnsat {
+		t.Errorf("got %d empty clauses, trivially unsat %v", feat.EmptyClauseCount, feat.TriviallyUnsat)
+	}
+	if feat.ClausesLengthLargest != length || feat.ClausesLengthMean != (length+1)/2.0 {
+		t.Errorf("got largest length %d and mean %f", feat.ClausesLengthLargest, feat.ClausesLengthMean)
+	}
+	if feat.PositiveLiteralsInClauseLargest != length || feat.NegativeUnitClauseCount != 1 {
+		t.Errorf("got %d positive literals at most, %d negative units",
+			feat.PositiveLiteralsInClauseLargest, feat.NegativeUnitClauseCount)
+	}
+}
+
+// analyzeString analyzes content like a worker analyzes the file of job
+func analyzeString(t *testing.T, job work, content string) *output.Stats {
+	t.Helper()
+	pconf := input.NewParsingConfig()
+	pconf.IgnoreLines = []string{"c", "%"}
+	pconf.Filename = job.name()
+	pconf.Strict = job.strict
+	pconf.Report = input.NewReport()
+	stat := output.NewStats()
+	err := analyze(job, strings.NewReader(content), stat, pconf, stats.NewFeatureConfig())
+	if err != nil {
+		t.Fatalf("%s: %s", job.name(), err)
+	}
+	return stat
+}
+
+func TestHeaderFeatures(t *testing.T) {
+	cnf := "p cnf 5 3\n1 -2 0\n2 3 0\nx1 4 0\n"
+	tests := []struct {
+		job     work
+		content string
+		// featurings to check and their actual clauses and largest variable
+		fts  func(stat *output.Stats) []*output.Features
+		want [][2]uint64
+	}{
+		{work{input: "f.cnf"}, cnf,
+			func(stat *output.Stats) []*output.Features { return []*output.Features{&stat.Fts} },
+			[][2]uint64{{3, 4}}},
+		{work{input: "f.cnf", stream: true}, cnf,
+			func(stat *output.Stats) []*output.Features { return []*output.Features{&stat.Fts} },
+			[][2]uint64{{3, 4}}},
+		{work{input: "f.cnf", wide: true}, cnf,
+			func(stat *output.Stats) []*output.Features { return []*output.Features{&stat.Fts} },
+			[][2]uint64{{3, 4}}},
+		{work{input: "f.cnf", simplify: true}, "p cnf 3 3\n1 0\n-1 2 3 0\n-2 -3 0\n",
+			func(stat *output.Stats) []*output.Features { return []*output.Features{&stat.Fts, stat.FtsSimplified} },
+			[][2]uint64{{3, 3}, {2, 3}}},
+		{work{input: "f.wcnf"}, "p wcnf 3 2 10\n10 1 -2 0\n1 3 0\n",
+			func(stat *output.Stats) []*output.Features {
+				return []*output.Features{&stat.Fts, stat.FtsHard, stat.FtsSoft}
+			},
+			[][2]uint64{{2, 3}, {1, 2}, {1, 3}}},
+		{work{input: "f.qdimacs"}, "p cnf 3 2\na 1 0\ne 2 3 0\n1 -2 0\n2 3 0\n",
+			func(stat *output.Stats) []*output.Features { return []*output.Features{&stat.Fts} },
+			[][2]uint64{{2, 3}}},
+		{work{input: "f.icnf"}, "p inccnf\n1 -2 0\na 1 0\n2 3 0\na -3 0\n",
+			func(stat *output.Stats) []*output.Features {
+				return []*output.Features{&stat.Fts, &stat.Increments[0].Fts, &stat.Increments[1].Fts}
+			},
+			[][2]uint64{{2, 3}, {1, 2}, {2, 3}}},
+		{work{input: "f.opb"}, "* #variable= 4 #constraint= 2\n+1 x1 +1 x2 >= 1;\n+2 x3 +1 x4 >= 2;\n",
+			func(stat *output.Stats) []*output.Features { return []*output.Features{&stat.Fts} },
+			[][2]uint64{{2, 4}}},
+	}
+
+	for _, test := range tests {
+		stat := analyzeString(t, test.job, test.content)
+		for i, feat := range test.fts(stat) {
+			got := [2]uint64{feat.ActualClausesCount, feat.MaxVariable}
+			if got != test.want[i] {
+				t.Errorf("%s (stream %v, simplify %v): featuring %d has actual clauses and largest variable %v, expected %v",
+					test.job.input, test.job.stream, test.job.simplify, i, got, test.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/stats"
+)
+
+func evaluateWeights(weights []uint64, wf *output.WeightFeatures) error {
+	// evaluating features: {WeightsDistinctCount, WeightsEntropy,
+	//   WeightsLargest, WeightsMean, WeightsSd, WeightsSmallest}
+	if len(weights) == 0 {
+		return nil
+	}
+
+	var acc stats.Welford
+	occurences := make(map[uint64]int)
+	wf.WeightsSmallest = weights[0]
+	for _, w := range weights {
+		if w < wf.WeightsSmallest {
+			wf.WeightsSmallest = w
+		}
+		if w > wf.WeightsLargest {
+			wf.WeightsLargest = w
+		}
+		acc.Add(float64(w))
+		occurences[w] += 1
+	}
+	wf.WeightsMean = acc.Mean()
+	wf.WeightsSd = acc.Stdev()
+
+	// entropy of the distribution of weight values
+	probs := make([]float64, 0, len(occurences))
+	for _, count := range occurences {
+		probs = append(probs, float64(count)/float64(len(weights)))
+	}
+	entropy, err := stats.EntropyFloat64(probs)
+	if err != nil {
+		return err
+	}
+	wf.WeightsEntropy = entropy
+	wf.WeightsDistinctCount = uint64(len(occurences))
+
+	return nil
+}
+
+// evaluateWCNF evaluates the features of the whole formula, of hard
+// clauses and of soft clauses as well as the weight features
+func evaluateWCNF(wcnf *sat.WCNF, stat *output.Stats, fconf *stats.FeatureConfig) error {
+	err := evaluate(wcnf.CNF, &stat.Fts, fconf)
+	if err != nil {
+		return err
+	}
+
+	hard, soft, weights := wcnf.Partition()
+	stat.Weights = output.NewWeightFeatures()
+	stat.Weights.HardClausesCount = uint64(hard.NbClauses)
+	stat.Weights.SoftClausesCount = uint64(soft.NbClauses)
+	stat.Weights.Top = wcnf.Top
+
+	if hard.NbClauses > 0 {
+		stat.FtsHard = output.NewFeatures()
+		err = evaluate(hard, stat.FtsHard, fconf)
+		if err != nil {
+			return err
+		}
+		evaluateActual(hard, stat.FtsHard)
+	}
+	if soft.NbClauses > 0 {
+		stat.FtsSoft = output.NewFeatures()
+		err = evaluate(soft, stat.FtsSoft, fconf)
+		if err != nil {
+			return err
+		}
+		evaluateActual(soft, stat.FtsSoft)
+	}
+
+	return evaluateWeights(weights, stat.Weights)
+}
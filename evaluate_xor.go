@@ -0,0 +1,120 @@
+package main
+
+import (
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/stats"
+)
+
+// XOR constraints longer than xorCutLength are split when expanded
+// into clauses, since a constraint of length k yields 2^(k-1) clauses
+const xorCutLength = 5
+
+// XOR systems whose matrix exceeds rankLimit words or whose Gaussian
+// elimination may take more than rankWorkLimit word operations
+// (rows × min(rows, columns) × words) are not reduced; their rank is
+// reported as -1
+const (
+	rankLimit     = 1 << 24
+	rankWorkLimit = 1 << 32
+)
+
+// occuringVariables returns whether variable v occurs in lits at index v
+func occuringVariables[L sat.Literal](lits []L) []bool {
+	occurs := make([]bool, 0)
+	for _, lit := range lits {
+		v := int(lit)
+		if v < 0 {
+			v = -v
+		}
+		for len(occurs) <= v {
+			occurs = append(occurs, false)
+		}
+		occurs[v] = true
+	}
+	return occurs
+}
+
+func evaluateXORs[L sat.Literal](xors []L, inCNF []bool, feat *output.XORFeatures) error {
+	// evaluating features: {SharedVariablesCount, SharedVariablesFraction,
+	//   VariablesCount, XORsCount, XORsLength*, XORsRank}
+	var err error
+
+	// column of each variable in the XOR system, -1 if it does not occur
+	column := make([]int32, 0)
+	lengths := make([]uint32, 0)
+	for _, xor := range sat.SplitClauses(xors) {
+		lengths = append(lengths, clauseSize(len(xor)))
+		for _, lit := range xor {
+			v := int(lit)
+			if v < 0 {
+				v = -v
+			}
+			for len(column) <= v {
+				column = append(column, -1)
+			}
+			if column[v] < 0 {
+				column[v] = int32(feat.VariablesCount)
+				feat.VariablesCount += 1
+				if v < len(inCNF) && inCNF[v] {
+					feat.SharedVariablesCount += 1
+				}
+			}
+		}
+	}
+	feat.XORsCount = uint64(len(lengths))
+	if len(lengths) == 0 {
+		return nil
+	}
+	if feat.VariablesCount > 0 {
+		feat.SharedVariablesFraction = float64(feat.SharedVariablesCount) / float64(feat.VariablesCount)
+	}
+
+	// store length features
+	feat.XORsLengthLargest, err = stats.LargestUint32(lengths)
+	if err != nil {
+		return err
+	}
+	feat.XORsLengthMean, err = stats.MeanUint32(lengths)
+	if err != nil {
+		return err
+	}
+	feat.XORsLengthMedian, err = stats.MedianUint32(lengths)
+	if err != nil {
+		return err
+	}
+	feat.XORsLengthSd, err = stats.StdevUint32(lengths, feat.XORsLengthMean)
+	if err != nil {
+		return err
+	}
+	feat.XORsLengthSmallest, err = stats.SmallestUint32(lengths)
+	if err != nil {
+		return err
+	}
+
+	// rank of the XOR system; a variable occuring twice cancels out
+	words := (int(feat.VariablesCount) + 63) / 64
+	work := float64(len(lengths)) * float64(min(len(lengths), int(feat.VariablesCount))) * float64(words)
+	if len(lengths)*words > rankLimit || work > rankWorkLimit {
+		feat.XORsRank = -1
+		return nil
+	}
+	matrix := make([]uint64, len(lengths)*words)
+	rows := make([][]uint64, len(lengths))
+	for r := range rows {
+		rows[r] = matrix[r*words : (r+1)*words]
+	}
+	for r, xor := range sat.SplitClauses(xors) {
+		for _, lit := range xor {
+			v := int(lit)
+			if v < 0 {
+				v = -v
+			}
+			c := column[v]
+			rows[r][c/64] ^= 1 << uint(c%64)
+		}
+	}
+	feat.XORsRank = int64(stats.RankGF2(rows, int(feat.VariablesCount)))
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package input
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// archive formats recognized by ArchiveFormat
+const (
+	NoArchive  = ""
+	TarArchive = "tar"
+	ZipArchive = "zip"
+)
+
+// MemberSeparator separates the path of an archive and the path of
+// a member inside, like "archive.tar.gz!path/inside.cnf"
+const MemberSeparator = "!"
+
+// MaxMemberSize is the size in bytes up to which the content of an
+// archive member is read into memory
+var MaxMemberSize int64 = 1 << 30
+
+// ErrMemberTooLarge is the error of members exceeding MaxMemberSize
+var ErrMemberTooLarge = errors.New("archive member too large")
+
+// Member is a regular file inside an archive
+type Member struct {
+	Name string
+	Data []byte
+	// Err is set instead of Data if the content exceeds MaxMemberSize
+	Err error
+}
+
+// readMember reads the content of the member name from r
+func readMember(name string, r io.Reader) (*Member, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxMemberSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > MaxMemberSize {
+		err = fmt.Errorf("%w: exceeds %d bytes", ErrMemberTooLarge, MaxMemberSize)
+		return &Member{Name: name, Err: err}, nil
+	}
+	return &Member{Name: name, Data: data}, nil
+}
+
+// ArchiveFormat determines the archive format of the file at path
+// by its extension. Compressed tar archives (like .tar.gz or .tgz)
+// are tar archives.
+func ArchiveFormat(path string) string {
+	lower := strings.ToLower(path)
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tar.xz", ".txz"} {
+		if strings.HasSuffix(lower, ext) {
+			return TarArchive
+		}
+	}
+	if strings.HasSuffix(lower, ".zip") {
+		return ZipArchive
+	}
+	return NoArchive
+}
+
+// WalkArchive calls fn for every regular file in the archive at path
+// whose name is accepted, in the order of the archive. The content of
+// a member is read into memory before fn is called, since members of
+// (compressed) tar archives can only be read sequentially. Members
+// exceeding MaxMemberSize are passed with Err instead of their content.
+func WalkArchive(path string, accept func(name string) bool, fn func(m *Member) error) error {
+	switch ArchiveFormat(path) {
+	case TarArchive:
+		return walkTar(path, accept, fn)
+	case ZipArchive:
+		return walkZip(path, accept, fn)
+	}
+	return &os.PathError{Op: "walk", Path: path, Err: os.ErrInvalid}
+}
+
+func walkTar(path string, accept func(name string) bool, fn func(m *Member) error) error {
+	fd, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	r, _, err := Decompress(fd)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || !accept(hdr.Name) {
+			continue
+		}
+		m, err := readMember(hdr.Name, tr)
+		if err != nil {
+			return err
+		}
+		err = fn(m)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func walkZip(path string, accept func(name string) bool, fn func(m *Member) error) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() || !accept(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		m, err := readMember(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		err = fn(m)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+
+	"github.com/ulikunitz/xz"
+)
+
+// compression formats recognized by Decompress
+const (
+	NoCompression    = ""
+	GzipCompression  = "gzip"
+	Bzip2Compression = "bzip2"
+	XzCompression    = "xz"
+)
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte{'B', 'Z', 'h'}
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+)
+
+// Decompress inspects the magic bytes at the beginning of fd and
+// wraps it into a decompressing reader if gzip, bzip2 or xz data is
+// found. Uncompressed data is passed through. The second return value
+// is the name of the detected compression or NoCompression.
+func Decompress(fd io.Reader) (io.Reader, string, error) {
+	br := bufio.NewReader(fd)
+	head, err := br.Peek(len(xzMagic))
+	if err != nil && err != io.EOF {
+		return nil, NoCompression, err
+	}
+
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		r, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, GzipCompression, err
+		}
+		return r, GzipCompression, nil
+	case bytes.HasPrefix(head, bzip2Magic):
+		return bzip2.NewReader(br), Bzip2Compression, nil
+	case bytes.HasPrefix(head, xzMagic):
+		r, err := xz.NewReader(br)
+		if err != nil {
+			return nil, XzCompression, err
+		}
+		return r, XzCompression, nil
+	}
+
+	return br, NoCompression, nil
+}
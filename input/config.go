@@ -4,6 +4,47 @@ type ParsingConfig struct {
 	IgnoreLines    []string
 	CheckNbVars    bool
 	CheckNbClauses bool
+	// Filename is reported in a ParseError
+	Filename string
+	// Lenient repairs malformed input instead of failing:
+	// a missing 0 after the last clause is added, header values
+	// not matching the content are replaced, junk tokens, duplicate
+	// headers and anything after the "%" trailer are skipped
+	Lenient bool
+	// Strict fails if the header values differ from the actual values,
+	// i.e. also if the largest variable is smaller than declared;
+	// it implies CheckNbVars and CheckNbClauses
+	Strict bool
+	// Comments collects the content of ignored lines in Report
+	Comments bool
+	// Report is filled by the parser if non-nil
+	Report *Report
+}
+
+// Report collects information about a parsed file besides the formula
+type Report struct {
+	// Repairs lists all problems repaired in lenient mode
+	Repairs []*ParseError
+	// Comments lists the content of ignored lines without prefix and
+	// surrounding whitespace if ParsingConfig.Comments is set;
+	// CommentBytes counts the bytes of these lines
+	Comments     []string
+	CommentBytes int
+	// DeclaredVars and DeclaredClauses are the values of the header
+	// before any repair, -1 for files without header values.
+	// ActualVars is the largest variable and ActualClauses the number
+	// of clauses (including XOR constraints) or OPB constraints found.
+	DeclaredVars    int
+	DeclaredClauses int
+	ActualVars      int
+	ActualClauses   int
+}
+
+func NewReport() *Report {
+	r := new(Report)
+	r.DeclaredVars = -1
+	r.DeclaredClauses = -1
+	return r
 }
 
 func NewParsingConfig() *ParsingConfig {
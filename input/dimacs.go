@@ -1,89 +1,271 @@
 package input
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/prokls/cnf-analysis-go/sat"
 )
 
+// ClauseHandler receives the clauses of a streamed CNF file one at a time.
+// The slice passed is reused by the parser and must not be retained.
+type ClauseHandler func(clause []sat.Lit) error
+
 type parsingState struct {
+	handler      ClauseHandler
+	wcnf         *sat.WCNF
+	headerless   bool
+	weightRead   bool
+	qbf          *sat.QBF
+	quantifier   sat.Quantifier
+	icnf         *sat.ICNF
+	assumption   bool
+	xor          bool
+	xors         int
 	clauses      int
 	variables    int
 	lineno       int
 	col          int
-	mode         int8
+	mode         int8 // 0-3 header, 4 clauses, 5 WCNF top, 6-8 trailer
 	inIgnoreLine bool
-	bufIndex     uint8
-	wasZero      bool
-	wordBuf      [20]byte
+	// trailerZero is set once the 0 after the "%" trailer was read
+	trailerZero bool
+	wordLen     int // length of the current token, which might exceed wordBuf
+	wordBuf     [20]byte
+	filename    string
+	report      *Report
+	// position of the current token; lineno and col are zero-based
+	// and incremented before considering a line or byte respectively
+	wordLine      int
+	wordCol       int
+	nbVarsLine    int
+	nbVarsCol     int
+	nbClausesLine int
+	nbClausesCol  int
+	// chunked is set if the state tokenizes a chunk of the clauses
+	// (see parallel.go); assumedClauseStart is set if the chunk was
+	// tokenized assuming it does not begin within a clause
+	chunked            bool
+	assumedClauseStart bool
+	// content of the current ignored line and column of its prefix,
+	// if comments are collected
+	comment    []byte
+	commentCol int
 }
 
 func isNewline(c byte) bool {
 	return c == 10
 }
 
+// isWhitespace tells whether c separates tokens. NUL bytes are
+// considered whitespace since some files are padded with them.
 func isWhitespace(c byte) bool {
-	return c == 9 || c == 10 || c == 11 || c == 12 || c == 13 || c == 32
+	return c == 0 || c == 9 || c == 10 || c == 11 || c == 12 || c == 13 || c == 32
+}
+
+// isIntegerPrefix tells whether word is the beginning of an integer
+func isIntegerPrefix(word []byte) bool {
+	for i, c := range word {
+		if (c < '0' || c > '9') && (i > 0 || (c != '-' && c != '+')) {
+			return false
+		}
+	}
+	return true
 }
 
-func unexpected(unexp, exp string, ps *parsingState) error {
-	// lineno is incremented before considering the line and zero-based, therefore ps.lineno
-	// col is incremented after considering the byte and zero-based, therefore ps.col+1
-	return fmt.Errorf("Unexpected '%s', expected %s, at line %d col %d", unexp, exp, ps.lineno, ps.col+1)
+// integerError converts an error of strconv into a ParseError
+func integerError(word string, err error, st *parsingState) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return withToken(st, TokenTooLong, word, "integer '%s' is out of range", word)
+	}
+	if st.mode < 4 {
+		return withToken(st, BadHeader, word, "Unexpected '%s', expected integer of header", word)
+	}
+	return withToken(st, NonInteger, word, "Unexpected '%s', expected integer", word)
 }
 
-func withPos(ps *parsingState, msg string, args ...interface{}) error {
-	// lineno is incremented before considering the line and zero-based, therefore ps.lineno
-	// col is incremented after considering the byte and zero-based, therefore ps.col+1
-	return fmt.Errorf(msg+fmt.Sprintf(", line %d, col %d", ps.lineno, ps.col+1), args...)
+// leadingZero returns the index of the leading zero of the integer
+// word or -1 if word is no integer with a leading zero
+func leadingZero(word []byte) int {
+	i := 0
+	if len(word) > 0 && (word[0] == '-' || word[0] == '+') {
+		i = 1
+	}
+	if i+1 < len(word) && word[i] == '0' && isIntegerPrefix(word) {
+		return i
+	}
+	return -1
 }
 
-func consumeByte(char byte, cnf *sat.CNF, st *parsingState, conf *ParsingConfig) error {
+func consumeByte[L sat.Literal](char byte, cnf *sat.Formula[L], st *parsingState, conf *ParsingConfig) error {
 	st.col += 1
 
 	if isNewline(char) {
+		if st.inIgnoreLine && conf.Comments {
+			addComment(st, st.col-st.commentCol)
+		}
 		st.inIgnoreLine = false
 		st.lineno += 1
 		st.col = 0
-		return consumeWord(cnf, st, conf, isNewline(char))
+		err := consumeWord(cnf, st, conf, isNewline(char))
+		if err == nil && st.mode == 5 {
+			// WCNF header without top value, hence no hard clauses
+			st.wcnf.Top = math.MaxUint64
+			st.mode = 4
+		}
+		if err == nil && st.mode == 6 {
+			// the line of the trailer ends
+			st.mode = 7
+		}
+		if err == nil && st.xor {
+			// XOR constraints end at the end of their line
+			if !conf.Lenient {
+				return withPos(st, MissingTerminator, "Missing 0 to terminate XOR constraint")
+			}
+			repair(st, MissingTerminator, "", "added missing 0 to terminate XOR constraint")
+			cnf.XORs = append(cnf.XORs, 0)
+			st.xor = false
+			st.xors += 1
+		}
+		return err
 	}
-	if st.inIgnoreLine {
+	if st.inIgnoreLine || st.mode == 6 || st.mode == 8 {
+		if st.inIgnoreLine && conf.Comments {
+			st.comment = append(st.comment, char)
+		}
 		return nil
 	}
 	if !isWhitespace(char) {
-		st.wordBuf[st.bufIndex] = char
-		st.bufIndex += 1
+		if st.wordLen == 0 {
+			st.wordLine = st.lineno + 1
+			st.wordCol = st.col
+		}
+		// tokens of arbitrary length are accepted, but only their
+		// beginning is retained; leading zeros of integers are dropped
+		// to retain their digits instead
+		if st.wordLen < len(st.wordBuf) {
+			st.wordBuf[st.wordLen] = char
+		} else if i := leadingZero(st.wordBuf[:]); i >= 0 && '0' <= char && char <= '9' {
+			copy(st.wordBuf[i:], st.wordBuf[i+1:])
+			st.wordBuf[len(st.wordBuf)-1] = char
+			return nil
+		}
+		st.wordLen += 1
 		return nil
 	}
 
 	return consumeWord(cnf, st, conf, isNewline(char))
 }
 
-func consumeWord(cnf *sat.CNF, st *parsingState, conf *ParsingConfig, nl bool) error {
-	if st.bufIndex == 0 {
+func consumeWord[L sat.Literal](cnf *sat.Formula[L], st *parsingState, conf *ParsingConfig, nl bool) error {
+	if st.wordLen == 0 {
 		return nil
 	}
+	if st.wordLen > len(st.wordBuf) {
+		return consumeLongWord(st, conf)
+	}
 
-	word := string(st.wordBuf[:st.bufIndex])
-	st.bufIndex = 0
+	word := string(st.wordBuf[:st.wordLen])
+	st.wordLen = 0
+	if st.mode == 6 || st.mode == 8 {
+		return nil
+	}
+	if st.mode == 4 && word == "%" {
+		// trailer of SATLIB files ("%\n0\n"), which must not be read
+		// as empty clause; the rest of its line is skipped
+		st.mode = 6
+		return nil
+	}
 	for i := 0; i < len(conf.IgnoreLines); i++ {
 		if word == conf.IgnoreLines[i] {
 			if !nl {
 				st.inIgnoreLine = true
+				st.commentCol = st.wordCol
+			} else if conf.Comments {
+				addComment(st, len(word))
 			}
 			return nil
 		}
 	}
+	if st.mode == 7 {
+		return consumeTrailer(word, st, conf)
+	}
+
+	if st.wcnf != nil {
+		if st.mode == 0 && word != "p" {
+			// WCNF format since 2022 has no header
+			st.headerless = true
+			st.mode = 4
+		}
+		if st.mode == 5 || (st.mode == 4 && !st.weightRead) {
+			return consumeWeight(word, st, conf)
+		}
+	}
+
+	if st.qbf != nil && st.mode == 4 && st.quantifier == 0 && (word == "e" || word == "a") {
+		if st.clauses > 0 || len(cnf.Lits) > 0 {
+			if conf.Lenient {
+				repair(st, MisplacedQuantifier, word, "skipped quantifier line after clauses")
+				st.inIgnoreLine = !nl
+				return nil
+			}
+			return withToken(st, MisplacedQuantifier, word, "quantifier '%s' must precede all clauses", word)
+		}
+		st.quantifier = sat.Quantifier(word[0])
+		return nil
+	}
+
+	if st.icnf != nil && st.mode == 4 && !st.assumption && word == "a" {
+		// assumption line of iCNF ("a 1 -2 0"); within a clause,
+		// "a" is a junk token
+		if len(cnf.Lits) == 0 || cnf.Lits[len(cnf.Lits)-1] == 0 {
+			st.icnf.Increments = append(st.icnf.Increments, sat.Increment{})
+			st.assumption = true
+			return nil
+		}
+	}
+
+	if st.mode == 4 && st.wcnf == nil && st.qbf == nil && st.icnf == nil && !st.xor && word[0] == 'x' {
+		// XOR constraint of CryptoMiniSat, "x1 -2 3 0" or "x 1 -2 3 0";
+		// within a clause, lenient mode skips it as junk token below
+		inClause := len(cnf.Lits) > 0 && cnf.Lits[len(cnf.Lits)-1] != 0
+		if inClause && !conf.Lenient {
+			// other junk tokens are reported as non-integers below
+			if lit, err := strconv.Atoi(word[1:]); err == nil && lit != 0 {
+				return withToken(st, MisplacedXOR, word, "XOR constraint must not begin within a clause")
+			}
+		}
+		if !inClause {
+			if st.chunked && st.clauses == 0 && len(cnf.Lits) == 0 {
+				st.assumedClauseStart = true
+			}
+			st.xor = true
+			if len(word) == 1 {
+				return nil
+			}
+			word = word[1:]
+		}
+	}
 
 	var integer int
 	if st.mode >= 2 {
 		i, err := strconv.Atoi(word)
+		if err != nil && conf.Lenient && st.mode == 4 {
+			if word == "p" {
+				repair(st, BadHeader, word, "skipped duplicate header")
+				st.inIgnoreLine = !nl
+			} else {
+				repair(st, NonInteger, word, "skipped junk token '%s'", word)
+			}
+			return nil
+		}
 		if err != nil {
-			return err
+			return integerError(word, err, st)
 		}
 		integer = i
 	}
@@ -95,24 +277,59 @@ func consumeWord(cnf *sat.CNF, st *parsingState, conf *ParsingConfig, nl bool) e
 		}
 		st.mode = 1
 	case 1:
-		if word != "cnf" {
+		if st.wcnf != nil {
+			if word != "wcnf" {
+				return unexpected(word, "'wcnf' of WCNF header", st)
+			}
+		} else if st.icnf != nil {
+			if word != "inccnf" {
+				return unexpected(word, "'inccnf' of iCNF header", st)
+			}
+			// iCNF header has no values
+			st.headerless = true
+			st.mode = 4
+			return nil
+		} else if word != "cnf" {
 			return unexpected(word, "'cnf' of CNF header", st)
 		}
 		st.mode = 2
 	case 2:
 		cnf.NbVars = integer
+		st.nbVarsLine, st.nbVarsCol = st.wordLine, st.wordCol
 		st.mode = 3
-		if integer >= math.MaxInt32 {
-			return withPos(st, "cannot consume more than %d variables", math.MaxInt32)
+		if integer >= sat.VarLimit[L]() {
+			return withPos(st, BadHeader, "cannot consume more than %d variables", sat.VarLimit[L]())
 		}
 	case 3:
 		cnf.NbClauses = integer
+		st.nbClausesLine, st.nbClausesCol = st.wordLine, st.wordCol
 		st.mode = 4
-		if integer >= math.MaxInt32 {
-			return withPos(st, "cannot consume more than %d clauses", math.MaxInt32)
+		if st.wcnf != nil {
+			st.mode = 5
+		}
+		if integer >= sat.VarLimit[L]() {
+			return withPos(st, BadHeader, "cannot consume more than %d clauses", sat.VarLimit[L]())
 		}
 	case 4:
-		cnf.Lits = append(cnf.Lits, sat.Lit(integer))
+		if integer > sat.VarLimit[L]() || integer < -sat.VarLimit[L]() {
+			return withToken(st, VariableOutOfRange, word, "%d exceeds the range of literals, %d variables at most", integer, sat.VarLimit[L]())
+		}
+		if st.quantifier != 0 {
+			return consumeQuantifiedVar(integer, cnf, st, conf)
+		}
+		if st.assumption {
+			return consumeAssumption(integer, cnf, st)
+		}
+		xor := st.xor
+		if xor {
+			cnf.XORs = append(cnf.XORs, L(integer))
+			if integer == 0 {
+				st.xor = false
+				st.xors += 1
+			}
+		} else {
+			cnf.Lits = append(cnf.Lits, L(integer))
+		}
 		if integer != 0 {
 			variable := integer
 			if variable < 0 {
@@ -121,42 +338,213 @@ func consumeWord(cnf *sat.CNF, st *parsingState, conf *ParsingConfig, nl bool) e
 			if variable > st.variables {
 				st.variables = variable
 			}
-			if conf.CheckNbVars {
-				if variable >= cnf.NbVars {
-					return withPos(st, "%d exceeds variable limit %d", variable, cnf.NbVars)
+			// lenient mode repairs the header after parsing
+			if (conf.CheckNbVars || conf.Strict) && !conf.Lenient && !st.headerless {
+				if variable > cnf.NbVars {
+					return withToken(st, VariableOutOfRange, strconv.Itoa(integer), "%d exceeds variable limit %d", variable, cnf.NbVars)
 				}
 			}
-			st.wasZero = false
-		} else {
+		} else if !xor {
 			st.clauses += 1
-			if st.wasZero {
-				return withPos(st, "Sorry, empty clauses (ie. clauses with no literals) are not allowed")
-			}
-			st.wasZero = true
+			st.weightRead = false
+			return handleClause(cnf, st)
+		}
+	}
+
+	return nil
+}
+
+// handleClause passes the clause terminated last to the handler
+// of StreamCNFFile and discards its literals
+func handleClause[L sat.Literal](cnf *sat.Formula[L], st *parsingState) error {
+	if st.handler == nil {
+		return nil
+	}
+	// only formulas of Lit are streamed
+	clause := any(cnf.Lits[:len(cnf.Lits)-1]).([]sat.Lit)
+	cnf.Lits = cnf.Lits[:0]
+	return st.handler(clause)
+}
+
+// addComment adds the content of the ignored line to the report.
+// size is the number of bytes of the line including its prefix.
+func addComment(st *parsingState, size int) {
+	if st.report != nil {
+		st.report.Comments = append(st.report.Comments, strings.TrimSpace(string(st.comment)))
+		st.report.CommentBytes += size
+	}
+	st.comment = st.comment[:0]
+}
+
+// consumeLongWord consumes a token exceeding wordBuf. It is neither a
+// keyword nor an integer in range, hence it is a junk token.
+func consumeLongWord(st *parsingState, conf *ParsingConfig) error {
+	st.wordLen = 0
+	if st.mode == 6 || st.mode == 8 {
+		return nil
+	}
+	token := string(st.wordBuf[:]) + "..."
+	if st.mode == 7 {
+		return consumeTrailer(token, st, conf)
+	}
+	if conf.Lenient && st.mode == 4 {
+		repair(st, TokenTooLong, token, "skipped token exceeding %d bytes", len(st.wordBuf))
+		return nil
+	}
+	if st.mode < 2 && st.wcnf == nil {
+		return unexpected(token, "CNF header", st)
+	}
+	if isIntegerPrefix(st.wordBuf[:]) {
+		return integerError(token, strconv.ErrRange, st)
+	}
+	return integerError(token, strconv.ErrSyntax, st)
+}
+
+// consumeTrailer consumes a token after the line of the "%" trailer,
+// which may only be followed by a single 0
+func consumeTrailer(word string, st *parsingState, conf *ParsingConfig) error {
+	if word == "0" && !st.trailerZero {
+		st.trailerZero = true
+		return nil
+	}
+	if conf.Lenient {
+		repair(st, MisplacedTrailer, word, "skipped everything after '%%' trailer")
+		st.mode = 8
+		return nil
+	}
+	return withToken(st, MisplacedTrailer, word, "Unexpected '%s' after '%%' trailer, clauses must precede it", word)
+}
+
+// consumeWeight consumes the top value of a WCNF header or
+// the weight at the beginning of a WCNF clause
+func consumeWeight(word string, st *parsingState, conf *ParsingConfig) error {
+	var weight uint64
+	if word == "h" && st.mode == 4 && st.headerless {
+		// hard clause, top is determined at the end
+		weight = math.MaxUint64
+	} else {
+		w, err := strconv.ParseUint(word, 10, 64)
+		if err != nil && conf.Lenient && st.mode == 4 {
+			repair(st, NonInteger, word, "skipped junk token '%s'", word)
+			return nil
+		}
+		if err != nil {
+			return integerError(word, err, st)
 		}
+		weight = w
 	}
 
+	if st.mode == 5 {
+		st.wcnf.Top = weight
+		st.mode = 4
+		return nil
+	}
+	st.wcnf.Weights = append(st.wcnf.Weights, weight)
+	st.weightRead = true
 	return nil
 }
 
+// consumeQuantifiedVar consumes a variable of a QDIMACS quantifier line.
+// Blocks with the same quantifier as the previous block are merged.
+func consumeQuantifiedVar[L sat.Literal](variable int, cnf *sat.Formula[L], st *parsingState, conf *ParsingConfig) error {
+	if variable == 0 {
+		st.quantifier = 0
+		return nil
+	}
+	if variable < 0 {
+		return withToken(st, VariableOutOfRange, strconv.Itoa(variable), "quantified variable %d must be positive", variable)
+	}
+	if (conf.CheckNbVars || conf.Strict) && variable > cnf.NbVars {
+		return withToken(st, VariableOutOfRange, strconv.Itoa(variable), "%d exceeds variable limit %d", variable, cnf.NbVars)
+	}
+	if variable > st.variables {
+		st.variables = variable
+	}
+
+	prefix := st.qbf.Prefix
+	if len(prefix) == 0 || prefix[len(prefix)-1].Quantifier != st.quantifier {
+		st.qbf.Prefix = append(prefix, sat.QuantifierBlock{Quantifier: st.quantifier})
+	}
+	block := &st.qbf.Prefix[len(st.qbf.Prefix)-1]
+	block.Vars = append(block.Vars, sat.Lit(variable))
+	return nil
+}
+
+// consumeAssumption consumes a literal of an iCNF assumption line.
+// The terminating 0 ends the current increment.
+func consumeAssumption[L sat.Literal](lit int, cnf *sat.Formula[L], st *parsingState) error {
+	inc := &st.icnf.Increments[len(st.icnf.Increments)-1]
+	if lit == 0 {
+		inc.NbVars = st.variables
+		inc.NbClauses = st.clauses
+		inc.NbLits = len(cnf.Lits)
+		st.assumption = false
+		return nil
+	}
+
+	inc.Assumptions = append(inc.Assumptions, sat.Lit(lit))
+	variable := lit
+	if variable < 0 {
+		variable = -variable
+	}
+	if variable > st.variables {
+		st.variables = variable
+	}
+	return nil
+}
+
+// ReadCNFFile parses a DIMACS CNF file and returns the CNF including
+// all its literals
 func ReadCNFFile(fd io.Reader, conf *ParsingConfig) (*sat.CNF, error) {
 	var st parsingState
-	cnf := sat.NewCNF()
+	return readCNF[sat.Lit](fd, conf, &st)
+}
 
-	// verify parameters
+// ReadWideCNFFile parses a DIMACS CNF file like ReadCNFFile, but
+// stores 64-bit literals to support more than math.MaxInt32 variables
+func ReadWideCNFFile(fd io.Reader, conf *ParsingConfig) (*sat.WideCNF, error) {
+	var st parsingState
+	return readCNF[sat.WideLit](fd, conf, &st)
+}
+
+// StreamCNFFile parses a DIMACS CNF file and passes each clause to
+// handler. Literals are not retained, hence the returned CNF only
+// carries the header values and memory consumption is independent
+// of the file size.
+func StreamCNFFile(fd io.Reader, conf *ParsingConfig, handler ClauseHandler) (*sat.CNF, error) {
+	var st parsingState
+	st.handler = handler
+	return readCNF[sat.Lit](fd, conf, &st)
+}
+
+// verifyConfig checks the parameters of conf
+func verifyConfig(conf *ParsingConfig, st *parsingState) error {
 	for i := 0; i < len(conf.IgnoreLines); i++ {
 		if len(conf.IgnoreLines[i]) >= len(st.wordBuf) {
-			return nil, withPos(&st, "line prefix '%s' is too long", conf.IgnoreLines[i])
+			return withPos(st, InvalidConfig, "line prefix '%s' is too long", conf.IgnoreLines[i])
 		}
 		if conf.IgnoreLines[i] == "p" {
-			return nil, withPos(&st, "p-headers cannot be ignored")
+			return withPos(st, InvalidConfig, "p-headers cannot be ignored")
 		}
 		for j := 0; j < len(conf.IgnoreLines[i]); j++ {
 			if isWhitespace(conf.IgnoreLines[i][j]) {
-				return nil, withPos(&st, "line prefixes must not contain spaces, '%s' does", conf.IgnoreLines[i])
+				return withPos(st, InvalidConfig, "line prefixes must not contain spaces, '%s' does", conf.IgnoreLines[i])
 			}
 		}
 	}
+	return nil
+}
+
+func readCNF[L sat.Literal](fd io.Reader, conf *ParsingConfig, st *parsingState) (*sat.Formula[L], error) {
+	cnf := sat.NewFormula[L]()
+	st.filename = conf.Filename
+	st.report = conf.Report
+
+	// verify parameters
+	err := verifyConfig(conf, st)
+	if err != nil {
+		return nil, err
+	}
 
 	// read ~4096 bytes
 	buf := make([]byte, os.Getpagesize())
@@ -166,7 +554,7 @@ func ReadCNFFile(fd io.Reader, conf *ParsingConfig) (*sat.CNF, error) {
 			return nil, err
 		}
 		for i := 0; i < n; i++ {
-			err = consumeByte(buf[i], cnf, &st, conf)
+			err = consumeByte(buf[i], cnf, st, conf)
 			if err != nil {
 				return nil, err
 			}
@@ -177,16 +565,176 @@ func ReadCNFFile(fd io.Reader, conf *ParsingConfig) (*sat.CNF, error) {
 	}
 
 	// terminate clause
-	consumeByte(byte('\n'), cnf, &st, conf)
-	if cnf.Lits[len(cnf.Lits)-1] != 0 {
-		return nil, fmt.Errorf("Missing 0 to terminate last clause")
+	err = consumeByte(byte('\n'), cnf, st, conf)
+	if err != nil {
+		return nil, err
+	}
+	err = finishCNF(cnf, st, conf)
+	if err != nil {
+		return nil, err
 	}
+	return cnf, nil
+}
 
-	if conf.CheckNbClauses {
-		if cnf.NbClauses != st.clauses {
-			return nil, fmt.Errorf("Expected %d clauses, got %d clauses", cnf.NbClauses, st.clauses)
+// finishCNF checks the CNF after the entire input has been consumed
+// and repairs it in lenient mode
+func finishCNF[L sat.Literal](cnf *sat.Formula[L], st *parsingState, conf *ParsingConfig) error {
+	if st.mode == 0 && st.wcnf == nil {
+		// empty files and files with comments only
+		st.wordLine, st.wordCol = 0, 0
+		return withPos(st, BadHeader, "Missing header")
+	}
+	if st.mode > 0 && st.mode < 4 {
+		return withPos(st, BadHeader, "Incomplete header")
+	}
+
+	if st.weightRead || len(cnf.Lits) > 0 && cnf.Lits[len(cnf.Lits)-1] != 0 {
+		if !conf.Lenient {
+			return withPos(st, MissingTerminator, "Missing 0 to terminate last clause")
+		}
+		if len(cnf.Lits) > 0 && cnf.Lits[len(cnf.Lits)-1] != 0 {
+			repair(st, MissingTerminator, "", "added missing 0 to terminate last clause")
+			cnf.Lits = append(cnf.Lits, 0)
+			st.clauses += 1
+			st.weightRead = false
+			err := handleClause(cnf, st)
+			if err != nil {
+				return err
+			}
+		} else {
+			repair(st, MissingTerminator, "", "skipped weight without clause")
+			st.wcnf.Weights = st.wcnf.Weights[:len(st.wcnf.Weights)-1]
+			st.weightRead = false
 		}
 	}
 
-	return cnf, nil
+	cnf.MaxVar = st.variables
+
+	// the header counts XOR constraints as clauses
+	clauses := st.clauses + st.xors
+	if st.report != nil {
+		if !st.headerless {
+			st.report.DeclaredVars = cnf.NbVars
+			st.report.DeclaredClauses = cnf.NbClauses
+		}
+		st.report.ActualVars = st.variables
+		st.report.ActualClauses = clauses
+	}
+	if conf.Strict && !st.headerless && cnf.NbVars != st.variables {
+		st.wordLine, st.wordCol = st.nbVarsLine, st.nbVarsCol
+		return withToken(st, VariableCountMismatch, strconv.Itoa(cnf.NbVars), "Expected %d variables, got largest variable %d", cnf.NbVars, st.variables)
+	}
+	if conf.Lenient && !st.headerless {
+		st.wordLine, st.wordCol = st.nbClausesLine, st.nbClausesCol
+		if cnf.NbClauses != clauses {
+			repair(st, ClauseCountMismatch, strconv.Itoa(cnf.NbClauses), "replaced %d clauses declared in header by actual %d clauses", cnf.NbClauses, clauses)
+			cnf.NbClauses = clauses
+		}
+		if cnf.NbVars < st.variables {
+			st.wordLine, st.wordCol = st.nbVarsLine, st.nbVarsCol
+			repair(st, VariableOutOfRange, strconv.Itoa(cnf.NbVars), "replaced %d variables declared in header by actual %d variables", cnf.NbVars, st.variables)
+			cnf.NbVars = st.variables
+		}
+	} else if (conf.CheckNbClauses || conf.Strict) && !st.headerless {
+		if cnf.NbClauses != clauses {
+			return &ParseError{
+				File:     st.filename,
+				Line:     st.nbClausesLine,
+				Col:      st.nbClausesCol,
+				Token:    strconv.Itoa(cnf.NbClauses),
+				Category: ClauseCountMismatch,
+				Msg:      fmt.Sprintf("Expected %d clauses, got %d clauses", cnf.NbClauses, clauses),
+			}
+		}
+	}
+
+	return nil
+}
+
+// ReadWCNFFile parses a weighted DIMACS file for MaxSAT. Both the
+// classic format with a "p wcnf nbvars nbclauses [top]" header and
+// the header-less format since 2022 with "h" marking hard clauses
+// are supported.
+func ReadWCNFFile(fd io.Reader, conf *ParsingConfig) (*sat.WCNF, error) {
+	var st parsingState
+	st.wcnf = sat.NewWCNF()
+
+	cnf, err := readCNF[sat.Lit](fd, conf, &st)
+	if err != nil {
+		return nil, err
+	}
+	st.wcnf.CNF = cnf
+
+	if st.headerless {
+		cnf.NbVars = st.variables
+		cnf.NbClauses = st.clauses
+
+		// top is one more than the sum of soft weights
+		var top uint64 = 1
+		for _, w := range st.wcnf.Weights {
+			if w != math.MaxUint64 {
+				if top+w < top {
+					top = math.MaxUint64
+					break
+				}
+				top += w
+			}
+		}
+		st.wcnf.Top = top
+		for i, w := range st.wcnf.Weights {
+			if w == math.MaxUint64 {
+				st.wcnf.Weights[i] = top
+			}
+		}
+	}
+
+	return st.wcnf, nil
+}
+
+// ReadQDIMACSFile parses a QDIMACS file, i.e. a DIMACS CNF file with
+// quantifier lines ("e 1 2 0" or "a 3 0") between header and clauses
+func ReadQDIMACSFile(fd io.Reader, conf *ParsingConfig) (*sat.QBF, error) {
+	var st parsingState
+	st.qbf = sat.NewQBF()
+
+	cnf, err := readCNF[sat.Lit](fd, conf, &st)
+	if err != nil {
+		return nil, err
+	}
+	if st.quantifier != 0 {
+		if !conf.Lenient {
+			return nil, withPos(&st, MissingTerminator, "Missing 0 to terminate last quantifier line")
+		}
+		repair(&st, MissingTerminator, "", "terminated last quantifier line")
+	}
+	st.qbf.Matrix = cnf
+
+	return st.qbf, nil
+}
+
+// ReadICNFFile parses an incremental CNF in the iCNF format, i.e. a
+// "p inccnf" header followed by clauses and assumption lines ("a 1 -2 0").
+// Every assumption line ends an increment, i.e. a solver call with all
+// clauses given so far. The header has no values, hence the number of
+// variables and clauses are determined from the content.
+func ReadICNFFile(fd io.Reader, conf *ParsingConfig) (*sat.ICNF, error) {
+	var st parsingState
+	st.icnf = sat.NewICNF()
+
+	cnf, err := readCNF[sat.Lit](fd, conf, &st)
+	if err != nil {
+		return nil, err
+	}
+	if st.assumption {
+		if !conf.Lenient {
+			return nil, withPos(&st, MissingTerminator, "Missing 0 to terminate last assumption line")
+		}
+		repair(&st, MissingTerminator, "", "terminated last assumption line")
+		consumeAssumption(0, cnf, &st)
+	}
+	cnf.NbVars = st.variables
+	cnf.NbClauses = st.clauses
+	st.icnf.CNF = cnf
+
+	return st.icnf, nil
 }
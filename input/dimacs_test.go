@@ -0,0 +1,76 @@
+package input
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+// dimacsSeeds are inputs covering the special cases of the tokenizer
+var dimacsSeeds = []string{
+	"p cnf 3 2\n1 -2 0\n2 3 0\n",
+	"p cnf 3 2\r\n1 -2 0\r\n2 3 0\r\n",
+	"p cnf 3 2\x00\n1 -2 0\x00\x00\n2 3 0\n\x00",
+	"p cnf 1 1\n" + strings.Repeat("1", 30) + " 0\n",
+	"p cnf 1 1\n" + strings.Repeat("x", 30) + " 0\n",
+	"p cnf 1 1\n0000000000000000000000001 0\n",
+	"p cnf 2 2\n1 -2 0\n2 0\n%\n0\n\n",
+	"p cnf 2 2\n1 -2 0\n%\n0\n2 0\n",
+	"p cnf 3 2\nx1 -2 3 0\n1 2 0\n",
+	"p cnf 3 2\nx 1 -2 3\n1 x2 0\n",
+	"p cnf 3 1\n1 x 0\n",
+	"p cnf 2 5\n1 junk 2 0\np cnf 2 1\n-1",
+	"c comment only\n",
+	"",
+}
+
+func FuzzReadCNFFile(f *testing.F) {
+	for _, seed := range dimacsSeeds {
+		f.Add([]byte(seed), false)
+		f.Add([]byte(seed), true)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, lenient bool) {
+		newConf := func() *ParsingConfig {
+			conf := NewParsingConfig()
+			conf.Filename = "fuzz.cnf"
+			conf.Lenient = lenient
+			conf.Report = NewReport()
+			return conf
+		}
+
+		cnf, err := ReadCNFFile(bytes.NewReader(data), newConf())
+		if err != nil {
+			if _, ok := err.(*ParseError); !ok {
+				t.Fatalf("error %q is no ParseError", err)
+			}
+		}
+
+		// the parallel parser must yield the same result
+		pcnf, perr := ReadCNFFileParallel(bytes.NewReader(data), newConf(), 2)
+		if (err == nil) != (perr == nil) || (err != nil && err.Error() != perr.Error()) {
+			t.Fatalf("sequential error %v, parallel error %v", err, perr)
+		}
+		if err == nil && !equalCNF(cnf, pcnf) {
+			t.Fatalf("sequential %v, parallel %v", cnf, pcnf)
+		}
+	})
+}
+
+func equalCNF(a, b *sat.CNF) bool {
+	return a.NbVars == b.NbVars && a.NbClauses == b.NbClauses &&
+		slices.Equal(a.Lits, b.Lits) && slices.Equal(a.XORs, b.XORs)
+}
+
+func TestReadCNFFileLeadingZeros(t *testing.T) {
+	input := "p cnf 00000000000000000000000000002 1\n-0000000000000000000000000000002 0000000000000000000000001 0\n"
+	cnf, err := ReadCNFFile(strings.NewReader(input), NewParsingConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cnf.NbVars != 2 || !slices.Equal(cnf.Lits, []sat.Lit{-2, 1, 0}) {
+		t.Fatalf("got %d variables and literals %v", cnf.NbVars, cnf.Lits)
+	}
+}
@@ -0,0 +1,79 @@
+package input
+
+import "fmt"
+
+// ErrorCategory classifies a ParseError in a machine-readable way
+type ErrorCategory string
+
+const (
+	BadHeader             ErrorCategory = "bad_header"
+	NonInteger            ErrorCategory = "non_integer"
+	VariableOutOfRange    ErrorCategory = "variable_out_of_range"
+	MissingTerminator     ErrorCategory = "missing_terminator"
+	ClauseCountMismatch   ErrorCategory = "clause_count_mismatch"
+	VariableCountMismatch ErrorCategory = "variable_count_mismatch"
+	TokenTooLong          ErrorCategory = "token_too_long"
+	MisplacedQuantifier   ErrorCategory = "misplaced_quantifier"
+	MisplacedXOR          ErrorCategory = "misplaced_xor"
+	InvalidConfig         ErrorCategory = "invalid_config"
+	BadConstraint         ErrorCategory = "bad_constraint"
+	MisplacedTrailer      ErrorCategory = "misplaced_trailer"
+)
+
+// ParseError describes why an input file could not be parsed.
+// Line and Col are one-based and refer to the beginning of Token.
+// They are zero if the error does not refer to a position.
+// The error message begins with File unless it is empty.
+type ParseError struct {
+	File     string
+	Line     int
+	Col      int
+	Token    string
+	Category ErrorCategory
+	Msg      string
+}
+
+func (e *ParseError) Error() string {
+	msg := e.Msg
+	if e.File != "" {
+		msg = e.File + ": " + msg
+	}
+	if e.Line > 0 {
+		msg += fmt.Sprintf(", line %d, col %d", e.Line, e.Col)
+	}
+	return msg
+}
+
+func unexpected(unexp, exp string, ps *parsingState) error {
+	return withToken(ps, BadHeader, unexp, "Unexpected '%s', expected %s", unexp, exp)
+}
+
+// withPos returns a ParseError at the position of the current token
+func withPos(ps *parsingState, cat ErrorCategory, msg string, args ...interface{}) error {
+	return withToken(ps, cat, "", msg, args...)
+}
+
+// withToken returns a ParseError at the position of the current token,
+// which is given as token
+func withToken(ps *parsingState, cat ErrorCategory, token string, msg string, args ...interface{}) error {
+	return newParseError(ps, cat, token, msg, args...)
+}
+
+func newParseError(ps *parsingState, cat ErrorCategory, token string, msg string, args ...interface{}) *ParseError {
+	return &ParseError{
+		File:     ps.filename,
+		Line:     ps.wordLine,
+		Col:      ps.wordCol,
+		Token:    token,
+		Category: cat,
+		Msg:      fmt.Sprintf(msg, args...),
+	}
+}
+
+// repair records a problem which has been repaired in lenient mode
+// at the position of the current token
+func repair(ps *parsingState, cat ErrorCategory, token string, msg string, args ...interface{}) {
+	if ps.report != nil {
+		ps.report.Repairs = append(ps.report.Repairs, newParseError(ps, cat, token, msg, args...))
+	}
+}
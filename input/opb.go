@@ -0,0 +1,339 @@
+package input
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+type opbToken struct {
+	text string
+	line int
+	col  int
+}
+
+// opbLexer splits an OPB file into tokens. ";" is a token of its own
+// and lines starting with "*" are comments. The position of the token
+// returned last is stored in st.
+type opbLexer struct {
+	r      *bufio.Reader
+	st     *parsingState
+	tokens []opbToken
+	last   opbToken
+	// comments are collected in the report of st
+	comments bool
+	// header values of "* #variable= N #constraint= M", -1 if missing
+	nbVars        int
+	nbConstraints int
+}
+
+func newOPBLexer(fd io.Reader, st *parsingState) *opbLexer {
+	lex := new(opbLexer)
+	lex.r = bufio.NewReader(fd)
+	lex.st = st
+	lex.nbVars = -1
+	lex.nbConstraints = -1
+	return lex
+}
+
+// readLine tokenizes the next line, io.EOF is returned at the end of input
+func (lex *opbLexer) readLine() error {
+	line, err := lex.r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if err == io.EOF && len(line) == 0 {
+		return io.EOF
+	}
+	lex.st.lineno += 1
+
+	trimmed := strings.TrimLeft(line, " \t\r\v\f\x00")
+	if strings.HasPrefix(trimmed, "*") {
+		if lex.comments && lex.st.report != nil {
+			content := strings.TrimRight(trimmed, "\n")
+			lex.st.report.Comments = append(lex.st.report.Comments, strings.TrimSpace(content[1:]))
+			lex.st.report.CommentBytes += len(content)
+		}
+		return lex.readHeader(trimmed[1:])
+	}
+
+	start := -1
+	for i := 0; i <= len(line); i++ {
+		if i < len(line) && !isWhitespace(line[i]) && line[i] != ';' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			lex.tokens = append(lex.tokens, opbToken{line[start:i], lex.st.lineno, start + 1})
+			start = -1
+		}
+		if i < len(line) && line[i] == ';' {
+			lex.tokens = append(lex.tokens, opbToken{";", lex.st.lineno, i + 1})
+		}
+	}
+	return nil
+}
+
+// readHeader reads the number of variables and constraints
+// from a comment like "* #variable= 5 #constraint= 4"
+func (lex *opbLexer) readHeader(comment string) error {
+	fields := strings.Fields(comment)
+	for i := 0; i+1 < len(fields); i++ {
+		var target *int
+		switch fields[i] {
+		case "#variable=":
+			target = &lex.nbVars
+		case "#constraint=":
+			target = &lex.nbConstraints
+		default:
+			continue
+		}
+		n, err := strconv.Atoi(fields[i+1])
+		if err != nil || n < 0 {
+			lex.st.wordLine, lex.st.wordCol = lex.st.lineno, 1
+			return withToken(lex.st, BadHeader, fields[i+1], "Unexpected '%s', expected count of %s", fields[i+1], fields[i])
+		}
+		*target = n
+		if target == &lex.nbConstraints {
+			lex.st.nbClausesLine, lex.st.nbClausesCol = lex.st.lineno, 1
+		} else {
+			lex.st.nbVarsLine, lex.st.nbVarsCol = lex.st.lineno, 1
+		}
+	}
+	return nil
+}
+
+// next returns the next token, io.EOF is returned at the end of input
+func (lex *opbLexer) next() (string, error) {
+	for len(lex.tokens) == 0 {
+		err := lex.readLine()
+		if err != nil {
+			return "", err
+		}
+	}
+	tok := lex.tokens[0]
+	lex.tokens = lex.tokens[1:]
+	lex.last = tok
+	lex.st.wordLine, lex.st.wordCol = tok.line, tok.col
+	return tok.text, nil
+}
+
+// unread makes the token returned last available to next again
+func (lex *opbLexer) unread() {
+	lex.tokens = append([]opbToken{lex.last}, lex.tokens...)
+}
+
+// peek returns the next token without consuming it
+func (lex *opbLexer) peek() (string, error) {
+	for len(lex.tokens) == 0 {
+		err := lex.readLine()
+		if err != nil {
+			return "", err
+		}
+	}
+	return lex.tokens[0].text, nil
+}
+
+func isRelation(tok string) bool {
+	return tok == ">=" || tok == "=" || tok == "<="
+}
+
+func isOPBLiteral(tok string) bool {
+	return strings.HasPrefix(tok, "x") || strings.HasPrefix(tok, "~x")
+}
+
+// readOPBLiteral converts "x12" or "~x12" into a literal
+func readOPBLiteral(tok string, lex *opbLexer, conf *ParsingConfig) (sat.Lit, error) {
+	name := tok
+	negated := strings.HasPrefix(name, "~")
+	if negated {
+		name = name[1:]
+	}
+	if !strings.HasPrefix(name, "x") {
+		return 0, withToken(lex.st, BadConstraint, tok, "Unexpected '%s', expected literal", tok)
+	}
+	v, err := strconv.Atoi(name[1:])
+	if err != nil || v <= 0 || v >= 1<<31-1 {
+		return 0, withToken(lex.st, BadConstraint, tok, "Unexpected '%s', expected literal", tok)
+	}
+	if v > lex.st.variables {
+		lex.st.variables = v
+	}
+	if (conf.CheckNbVars || conf.Strict) && !conf.Lenient && lex.nbVars >= 0 && v > lex.nbVars {
+		return 0, withToken(lex.st, VariableOutOfRange, tok, "%d exceeds variable limit %d", v, lex.nbVars)
+	}
+	if negated {
+		return sat.Lit(-v), nil
+	}
+	return sat.Lit(v), nil
+}
+
+// readTerms reads a sum of linear terms and returns it with the
+// token terminating it, which is a relational operator or ";"
+func readTerms(lex *opbLexer, conf *ParsingConfig) ([]sat.PBTerm, string, error) {
+	terms := make([]sat.PBTerm, 0, 8)
+	for {
+		tok, err := lex.next()
+		if err == io.EOF {
+			return nil, "", withPos(lex.st, MissingTerminator, "Missing ';' to terminate constraint")
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if isRelation(tok) || tok == ";" {
+			return terms, tok, nil
+		}
+
+		coef, err := strconv.ParseInt(tok, 10, 64)
+		if err != nil {
+			if isOPBLiteral(tok) {
+				return nil, "", withToken(lex.st, BadConstraint, tok, "Unexpected '%s', expected coefficient", tok)
+			}
+			return nil, "", integerError(tok, err, lex.st)
+		}
+		tok, err = lex.next()
+		if err == io.EOF {
+			return nil, "", withPos(lex.st, MissingTerminator, "Missing ';' to terminate constraint")
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		lit, err := readOPBLiteral(tok, lex, conf)
+		if err != nil {
+			return nil, "", err
+		}
+		following, err := lex.peek()
+		if err == nil && isOPBLiteral(following) {
+			lex.next()
+			return nil, "", withToken(lex.st, BadConstraint, following, "non-linear terms are not supported")
+		}
+		terms = append(terms, sat.PBTerm{Coef: coef, Lit: lit})
+	}
+}
+
+// ReadOPBFile parses a pseudo-Boolean formula in the OPB format of
+// the pseudo-Boolean competitions. Linear constraints with relations
+// ">=", "=" and "<=" and a linear objective ("min:" or "max:") are
+// supported; coefficients and degrees must fit into 64 bits, also
+// after normalization to ">=" with positive coefficients.
+func ReadOPBFile(fd io.Reader, conf *ParsingConfig) (*sat.PBF, error) {
+	var st parsingState
+	st.filename = conf.Filename
+	st.report = conf.Report
+	st.mode = 4
+	lex := newOPBLexer(fd, &st)
+	lex.comments = conf.Comments
+	pbf := sat.NewPBF()
+
+	for {
+		tok, err := lex.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// objective function
+		if tok == "min:" || tok == "max:" {
+			if pbf.Objective != nil || len(pbf.Constraints) > 0 {
+				return nil, withToken(&st, BadConstraint, tok, "objective function must precede all constraints")
+			}
+			terms, end, err := readTerms(lex, conf)
+			if err != nil {
+				return nil, err
+			}
+			if end != ";" {
+				return nil, withToken(&st, BadConstraint, end, "Unexpected '%s', expected ';' after objective function", end)
+			}
+			pbf.Minimize = tok == "min:"
+			pbf.Objective = terms
+			continue
+		}
+
+		// constraint
+		lex.unread()
+		terms, rel, err := readTerms(lex, conf)
+		if err != nil {
+			return nil, err
+		}
+		if rel == ";" {
+			return nil, withToken(&st, BadConstraint, rel, "Unexpected ';', expected relational operator")
+		}
+		c := sat.PBConstraint{Terms: terms}
+		switch rel {
+		case ">=":
+			c.Relation = sat.GreaterEqual
+		case "=":
+			c.Relation = sat.Equal
+		case "<=":
+			c.Relation = sat.LessEqual
+		}
+
+		tok, err = lex.next()
+		if err == io.EOF {
+			return nil, withPos(&st, MissingTerminator, "Missing degree of constraint")
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.Degree, err = strconv.ParseInt(tok, 10, 64)
+		if err != nil {
+			return nil, integerError(tok, err, &st)
+		}
+		if _, err := c.Normalize(); err != nil {
+			return nil, withToken(&st, BadConstraint, tok, "coefficients of constraint exceed 64 bits when normalized")
+		}
+
+		tok, err = lex.next()
+		if err == io.EOF || (err == nil && tok != ";") {
+			if err == nil {
+				return nil, withToken(&st, MissingTerminator, tok, "Unexpected '%s', expected ';' to terminate constraint", tok)
+			}
+			return nil, withPos(&st, MissingTerminator, "Missing ';' to terminate constraint")
+		}
+		if err != nil {
+			return nil, err
+		}
+		pbf.Constraints = append(pbf.Constraints, c)
+	}
+
+	// header values
+	pbf.NbVars = lex.nbVars
+	pbf.NbConstraints = lex.nbConstraints
+	if st.report != nil {
+		st.report.DeclaredVars = lex.nbVars
+		st.report.DeclaredClauses = lex.nbConstraints
+		st.report.ActualVars = st.variables
+		st.report.ActualClauses = len(pbf.Constraints)
+	}
+	if conf.Strict && pbf.NbVars >= 0 && pbf.NbVars != st.variables {
+		st.wordLine, st.wordCol = st.nbVarsLine, st.nbVarsCol
+		return nil, withToken(&st, VariableCountMismatch, strconv.Itoa(pbf.NbVars), "Expected %d variables, got largest variable %d", pbf.NbVars, st.variables)
+	}
+	if pbf.NbVars < st.variables {
+		if pbf.NbVars >= 0 && conf.Lenient {
+			st.wordLine, st.wordCol = st.nbVarsLine, st.nbVarsCol
+			repair(&st, VariableOutOfRange, strconv.Itoa(pbf.NbVars), "replaced %d variables declared in header by actual %d variables", pbf.NbVars, st.variables)
+		}
+		pbf.NbVars = st.variables
+	}
+	if pbf.NbConstraints < 0 {
+		pbf.NbConstraints = len(pbf.Constraints)
+	} else if pbf.NbConstraints != len(pbf.Constraints) {
+		st.wordLine, st.wordCol = st.nbClausesLine, st.nbClausesCol
+		if conf.Lenient {
+			repair(&st, ClauseCountMismatch, strconv.Itoa(pbf.NbConstraints), "replaced %d constraints declared in header by actual %d constraints", pbf.NbConstraints, len(pbf.Constraints))
+			pbf.NbConstraints = len(pbf.Constraints)
+		} else if conf.CheckNbClauses || conf.Strict {
+			return nil, withToken(&st, ClauseCountMismatch, strconv.Itoa(pbf.NbConstraints), "Expected %d constraints, got %d constraints", pbf.NbConstraints, len(pbf.Constraints))
+		}
+	}
+
+	return pbf, nil
+}
@@ -0,0 +1,39 @@
+package input
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadOPBFileStrict(t *testing.T) {
+	tests := []struct {
+		content  string
+		category ErrorCategory
+	}{
+		{"* #variable= 3 #constraint= 2\n+1 x1 +1 x2 >= 1 ;\n+1 x3 >= 1 ;\n", ""},
+		{"* #variable= 4 #constraint= 2\n+1 x1 +1 x2 >= 1 ;\n+1 x3 >= 1 ;\n", VariableCountMismatch},
+		{"* #variable= 2 #constraint= 2\n+1 x1 +1 x2 >= 1 ;\n+1 x3 >= 1 ;\n", VariableOutOfRange},
+		{"* #variable= 3 #constraint= 1\n+1 x1 +1 x2 >= 1 ;\n+1 x3 >= 1 ;\n", ClauseCountMismatch},
+	}
+	for _, test := range tests {
+		conf := NewParsingConfig()
+		conf.Strict = true
+		_, err := ReadOPBFile(strings.NewReader(test.content), conf)
+		var category ErrorCategory
+		if err != nil {
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("error %q is no ParseError", err)
+			}
+			category = perr.Category
+		}
+		if category != test.category {
+			t.Errorf("%q: got error %v, expected category %q", test.content, err, test.category)
+		}
+
+		// the mismatches are accepted without --strict
+		if _, err := ReadOPBFile(strings.NewReader(test.content), NewParsingConfig()); err != nil {
+			t.Errorf("%q: got error %v without --strict", test.content, err)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+// size of the newline-aligned chunks tokenized by one goroutine each
+const chunkSize = 1 << 22
+
+// chunk is a newline-aligned part of the clauses of a DIMACS file.
+// Every chunk begins at the beginning of a line, hence it can be
+// tokenized independently of all preceding chunks. Clauses may span
+// several chunks; they are stitched by concatenating the literals.
+type chunk struct {
+	data []byte
+	last bool
+
+	lits   []sat.Lit
+	xors   []sat.Lit
+	st     parsingState
+	report *Report
+	err    error
+	done   chan struct{}
+}
+
+// parseChunk tokenizes c in clause mode. header provides the header
+// values for checks. inClause tells whether c begins within a clause.
+// Line numbers are relative to the chunk.
+func parseChunk(c *chunk, header *sat.CNF, conf *ParsingConfig, inClause bool) {
+	defer close(c.done)
+
+	cnf := sat.NewCNF()
+	cnf.NbVars = header.NbVars
+	cnf.NbClauses = header.NbClauses
+	if inClause {
+		// placeholder for the literals of preceding chunks
+		cnf.Lits = append(cnf.Lits, 1)
+	}
+	c.st.mode = 4
+	c.st.chunked = true
+	c.st.filename = conf.Filename
+	if conf.Report != nil {
+		c.report = NewReport()
+		c.st.report = c.report
+	}
+
+	for _, b := range c.data {
+		c.err = consumeByte(b, cnf, &c.st, conf)
+		if c.err != nil {
+			return
+		}
+	}
+	if c.last {
+		// terminate clause
+		c.err = consumeByte(byte('\n'), cnf, &c.st, conf)
+	}
+	c.lits = cnf.Lits
+	if inClause {
+		c.lits = c.lits[1:]
+	}
+	c.xors = cnf.XORs
+}
+
+// ReadCNFFileParallel parses a DIMACS CNF file like ReadCNFFile, but
+// tokenizes the clauses with units goroutines. The header is parsed
+// sequentially, the remaining input is split into newline-aligned
+// chunks which are parsed concurrently and merged in order. The
+// resulting CNF, errors and repairs are identical to ReadCNFFile.
+func ReadCNFFileParallel(fd io.Reader, conf *ParsingConfig, units int) (*sat.CNF, error) {
+	var st parsingState
+	cnf := sat.NewCNF()
+	st.filename = conf.Filename
+	st.report = conf.Report
+
+	// verify parameters
+	err := verifyConfig(conf, &st)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse header sequentially up to the end of its line
+	r := bufio.NewReader(fd)
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			err = consumeByte(byte('\n'), cnf, &st, conf)
+			if err == nil {
+				err = finishCNF(cnf, &st, conf)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return cnf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = consumeByte(c, cnf, &st, conf)
+		if err != nil {
+			return nil, err
+		}
+		if st.mode >= 4 && isNewline(c) {
+			break
+		}
+	}
+
+	// split remaining input into chunks and parse them concurrently
+	var wg sync.WaitGroup
+	ordered := make(chan *chunk, 2*units)
+	work := make(chan *chunk, units)
+	quit := make(chan struct{})
+	var readErr error
+
+	for i := 0; i < units; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range work {
+				parseChunk(c, cnf, conf, false)
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(ordered)
+		defer close(work)
+
+		var carry []byte
+		for {
+			buf := make([]byte, len(carry), len(carry)+chunkSize)
+			copy(buf, carry)
+			n, err := io.ReadFull(r, buf[len(carry):cap(buf)])
+			buf = buf[:len(carry)+n]
+			c := &chunk{done: make(chan struct{})}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				c.data = buf
+				c.last = true
+			} else if err != nil {
+				readErr = err
+				return
+			} else {
+				idx := bytes.LastIndexByte(buf, '\n')
+				if idx < 0 {
+					// line exceeds chunk, continue reading
+					carry = buf
+					continue
+				}
+				c.data = buf[:idx+1]
+				carry = buf[idx+1:]
+			}
+
+			select {
+			case ordered <- c:
+			case <-quit:
+				return
+			}
+			select {
+			case work <- c:
+			case <-quit:
+				return
+			}
+			if c.last {
+				return
+			}
+		}
+	}()
+
+	// merge chunks in order, line numbers are shifted by offset
+	offset := st.lineno
+	for c := range ordered {
+		<-c.done
+		if st.mode >= 6 {
+			// after the trailer, tokenize sequentially in its state
+			for _, b := range c.data {
+				err = consumeByte(b, cnf, &st, conf)
+				if err != nil {
+					break
+				}
+			}
+			if err == nil && c.last {
+				err = consumeByte(byte('\n'), cnf, &st, conf)
+			}
+			if err != nil {
+				break
+			}
+			offset = st.lineno
+			continue
+		}
+		if c.st.assumedClauseStart && len(cnf.Lits) > 0 && cnf.Lits[len(cnf.Lits)-1] != 0 {
+			// whether a chunk begins within a clause is relevant for
+			// XOR constraints only; tokenize again with the actual state
+			*c = chunk{data: c.data, last: c.last, done: make(chan struct{})}
+			parseChunk(c, cnf, conf, true)
+		}
+		if c.err != nil {
+			if perr, ok := c.err.(*ParseError); ok && perr.Line > 0 {
+				perr.Line += offset
+			}
+			err = c.err
+			break
+		}
+
+		cnf.Lits = append(cnf.Lits, c.lits...)
+		cnf.XORs = append(cnf.XORs, c.xors...)
+		st.clauses += c.st.clauses
+		st.xors += c.st.xors
+		if c.st.variables > st.variables {
+			st.variables = c.st.variables
+		}
+		if c.st.wordLine > 0 {
+			st.wordLine, st.wordCol = c.st.wordLine+offset, c.st.wordCol
+		}
+		if c.report != nil {
+			for _, rep := range c.report.Repairs {
+				if rep.Line > 0 {
+					rep.Line += offset
+				}
+				conf.Report.Repairs = append(conf.Report.Repairs, rep)
+			}
+			conf.Report.Comments = append(conf.Report.Comments, c.report.Comments...)
+			conf.Report.CommentBytes += c.report.CommentBytes
+		}
+		offset += c.st.lineno
+		st.lineno = offset
+		st.mode = c.st.mode
+		st.trailerZero = c.st.trailerZero
+	}
+	close(quit)
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	err = finishCNF(cnf, &st, conf)
+	if err != nil {
+		return nil, err
+	}
+	return cnf, nil
+}
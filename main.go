@@ -1,27 +1,48 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	input "github.com/prokls/cnf-analysis-go/input"
 	output "github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+	"github.com/prokls/cnf-analysis-go/solver"
 	"github.com/prokls/cnf-analysis-go/stats"
 )
 
 const USAGE = `usage: cnf-analysis-go [-h] [-f {xml,json}] [--ignore IGNORE] [-u UNITS] [-n]
-                       [-p] [-s]
+                       [--digests DIGESTS] [-p] [-s] [-o OUTPUT]
+                       [-P PARSE_UNITS] [--stream] [--wide]
+                       [--output-dir OUTPUT_DIR] [--json-errors]
+                       [--lenient] [--strict] [--expand-xors]
+                       [--include INCLUDE] [--exclude EXCLUDE] [--comments]
+                       [--simplify] [--solve] [--solve-conflicts CONFLICTS]
+                       [--solve-time SECONDS] [--probe] [--probe-seed SEED]
                        dimacsfiles [dimacsfiles ...]
 
 CNF analysis
 
 positional arguments:
-  dimacsfiles           filepath of DIMACS file
+  dimacsfiles           filepath of DIMACS file (.wcnf files are read
+                        as weighted MaxSAT instances, .qdimacs files
+                        as QBF instances, .icnf files as incremental
+                        CNFs, .opb files as pseudo-Boolean formulas);
+                        "-" reads from stdin. Members of archives
+                        (.tar, .tar.gz, .zip, ...) are analyzed
+                        one by one. Directories are walked
+                        recursively; "@list" reads paths from the
+                        file list, "@-" from stdin
 
 optional arguments:
   -h, --help            show this help message and exit
@@ -32,9 +53,55 @@ optional arguments:
                         how many units (= processes) should run in
                         concurrently
   -n, --no-hashes       do not compute hashes for the CNF file considered
+  --digests DIGESTS     comma-separated digests to compute out of md5,
+                        sha1, sha256, blake2b and cnfhash (default:
+                        md5,sha1,cnfhash)
   -p, --fullpath        use full path instead of basename in featurefiles
   -s, --skip-existing   skip CNF file if file.stats.json exists
-`
+  -o OUTPUT, --output OUTPUT
+                        file to store features of stdin in; "-" or
+                        omitted writes them to stdout
+  --include INCLUDE     glob pattern for base names of files considered
+                        in directories (like '*.cnf*')
+  --exclude EXCLUDE     glob pattern for base names of files and
+                        directories skipped in directories
+  --output-dir OUTPUT_DIR
+                        store features of archive members in separate
+                        files in OUTPUT_DIR instead of one JSON array
+                        per archive
+  -P PARSE_UNITS, --parse-units PARSE_UNITS
+                        how many goroutines should parse each DIMACS
+                        CNF file concurrently
+  --stream              evaluate clauses while parsing without keeping
+                        literals in memory (medians are approximated)
+  --wide                store literals of DIMACS CNF files with 64 bits
+                        to support more than 2147483647 variables
+  --json-errors         print errors as JSON diagnostics (one object per
+                        line) on stderr
+  --lenient             repair malformed DIMACS files and list repairs
+                        in @diagnostics
+  --strict              fail if the header values differ from the
+                        largest variable or the number of clauses
+  --expand-xors         expand XOR constraints into equivalent clauses
+                        before evaluating the features of "featuring"
+  --comments            collect comment lines and their "key: value"
+                        or "key=value" pairs in @comments
+  --simplify            also evaluate the features of DIMACS CNF files
+                        after unit propagation, pure literal elimination,
+                        tautology and duplicate literal removal and
+                        subsumption in "featuring_simplified"
+  --solve               run a CDCL solver on DIMACS CNF files and store
+                        its answer and effort in "solving"
+  --solve-conflicts CONFLICTS
+                        give up solving after CONFLICTS conflicts
+                        (default 100000, 0 for no limit); implies --solve
+  --solve-time SECONDS  give up solving after SECONDS seconds
+                        (default 10, 0 for no limit); implies --solve
+  --probe               make random DPLL probes on DIMACS CNF files and
+                        store the unit propagations per depth and an
+                        estimate of the search space in "probing"
+  --probe-seed SEED     seed of the random decisions of --probe
+                        (default 1); implies --probe`
 
 type work struct {
 	input       string
@@ -43,6 +110,143 @@ type work struct {
 	ignoreLines []string
 	fullpath    bool
 	hashes      bool
+	digests     []string
+	parseUnits  int
+	stream      bool
+	wide        bool
+	jsonErrors  bool
+	lenient     bool
+	strict      bool
+	expandXORs  bool
+	comments    bool
+	simplify    bool
+	// budget of the solver if --solve is given, otherwise nil
+	solve *solver.Budget
+	// configuration of the probes if --probe is given, otherwise nil
+	probe *solver.ProbeConfig
+	// member of the archive input; its content is data
+	member string
+	data   []byte
+	// collects the stats instead of writing them to output if non-nil
+	collected *collection
+	index     int
+}
+
+// name identifies the file considered by job
+func (job work) name() string {
+	if job.member != "" {
+		return job.input + input.MemberSeparator + job.member
+	}
+	return job.input
+}
+
+// collection gathers the stats of all members of an archive, which
+// are written to output as one JSON array in the order of the archive
+type collection struct {
+	mutex  sync.Mutex
+	output string
+	stats  []*output.Stats
+}
+
+// add reserves an index for the stats of the next member
+func (c *collection) add() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stats = append(c.stats, nil)
+	return len(c.stats) - 1
+}
+
+func (c *collection) set(index int, stat *output.Stats) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stats[index] = stat
+}
+
+// write writes the stats of all members processed successfully
+func (c *collection) write(format int) error {
+	oconf := output.NewOutputConfig()
+	oconf.Format = format
+	list := make([]*output.Stats, 0, len(c.stats))
+	for _, stat := range c.stats {
+		if stat != nil {
+			list = append(list, stat)
+		}
+	}
+
+	log.Printf("writing file %s", c.output)
+	out, err := createOutput(c.output)
+	if err != nil {
+		return err
+	}
+	err = output.WriteFeaturesList(list, out, oconf)
+	out.Close()
+	return err
+}
+
+// diagnostic converts a parse error or repair of file
+func diagnostic(file string, perr *input.ParseError) output.Diagnostic {
+	return output.Diagnostic{
+		File:     file,
+		Line:     perr.Line,
+		Col:      perr.Col,
+		Token:    perr.Token,
+		Category: string(perr.Category),
+		Message:  perr.Msg,
+	}
+}
+
+// openInput opens the file at path or returns stdin if path is "-"
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// openJobInput opens the input of job, which might be an archive member
+func openJobInput(job work) (io.ReadCloser, error) {
+	if job.member != "" {
+		return io.NopCloser(bytes.NewReader(job.data)), nil
+	}
+	return openInput(job.input)
+}
+
+// createOutput creates the file at path or returns stdout if path is "-"
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// report prints an error concerning job on stderr. context describes
+// what failed and category classifies errors other than parse errors.
+func report(job work, category string, context string, err error) {
+	var perr *input.ParseError
+	isParseError := errors.As(err, &perr)
+	if !job.jsonErrors {
+		if isParseError && perr.File != "" {
+			// the message of a ParseError begins with the file
+			fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", context, err.Error())
+		}
+		return
+	}
+
+	d := output.Diagnostic{File: job.name(), Category: category, Message: err.Error()}
+	if isParseError {
+		d = diagnostic(job.name(), perr)
+	}
+	output.WriteDiagnostic(&d, os.Stderr)
 }
 
 func worker(workDist chan work, w *sync.WaitGroup) {
@@ -56,72 +260,333 @@ func worker(workDist chan work, w *sync.WaitGroup) {
 
 		oconf.Format = job.format
 		pconf.IgnoreLines = job.ignoreLines
+		pconf.Filename = job.name()
+		pconf.Lenient = job.lenient
+		pconf.Strict = job.strict
+		pconf.Comments = job.comments
+		pconf.Report = input.NewReport()
 		fconf.FullPath = job.fullpath
+		fconf.Member = job.member
 		fconf.Hashes = job.hashes
+		fconf.Digests = job.digests
+		switch inputFormat(job.name()) {
+		case ".wcnf", ".qdimacs", ".icnf", ".opb":
+			fconf.NoCNFHash = true
+			if job.stream {
+				log.Printf("--stream is ignored for %s, only DIMACS CNF files are streamed", job.name())
+			}
+		}
 
 		if len(pconf.IgnoreLines) == 0 {
 			pconf.IgnoreLines = append(pconf.IgnoreLines, "c", "%")
 		}
 
-		log.Printf("considering %s", job.input)
+		log.Printf("considering %s", job.name())
 
 		// read file
-		fd, err := os.Open(job.input)
+		fd, err := openJobInput(job)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "read file %s failed: %s\n", job.input, err.Error())
-			return
+			report(job, "io", fmt.Sprintf("read file %s failed", job.name()), err)
+			continue
 		}
 
-		// parse file
-		cnf, err := input.ReadCNFFile(fd, pconf)
-		fd.Close()
+		// hashes are computed from the bytes being parsed:
+		// compressed digests the raw input, content the decompressed one
+		compressed := stats.NewDigester(fconf.CompressedDigests())
+		in, compression, err := input.Decompress(io.TeeReader(fd, compressed))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error while processing %s: %s\n", job.input, err.Error())
-			return
+			fd.Close()
+			compressed.Sums()
+			report(job, "decompression", fmt.Sprintf("could not decompress %s", job.name()), err)
+			continue
 		}
+		if compression == input.NoCompression {
+			// raw input equals content, digesting it twice is futile
+			compressed.Sums()
+		}
+		content := stats.NewDigester(fconf.ContentDigests())
+		in = io.TeeReader(in, content)
 
-		// evaluate features
+		// parse file and evaluate features
 		stat := output.NewStats()
-		err = stats.Metadata(stat, job.input, fconf)
+		err = analyze(job, in, stat, pconf, fconf)
+		digested := len(fconf.ContentDigests()) > 0 || len(fconf.CompressedDigests()) > 0
+		if err == nil && digested {
+			// the parser might stop early, but hashes cover the entire input
+			_, err = io.Copy(io.Discard, in)
+		}
+		fd.Close()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "could not determine metadata: %s\n", err.Error())
-			return
+			content.Sums()
+			compressed.Sums()
+			report(job, "processing", fmt.Sprintf("error while processing %s", job.name()), err)
+			continue
+		}
+		for _, r := range pconf.Report.Repairs {
+			stat.Diagnostics = append(stat.Diagnostics, diagnostic(job.name(), r))
+		}
+		if job.comments {
+			stats.Comments(stat, pconf.Report)
 		}
 
-		err = evaluate(cnf, &stat.Fts, fconf)
+		err = stats.Metadata(stat, job.input, compression, content, compressed, fconf)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "evaluation failed: %s\n", err.Error())
-			return
+			compressed.Sums()
+			report(job, "metadata", "could not determine metadata", err)
+			continue
+		}
+
+		if job.collected != nil {
+			job.collected.set(job.index, stat)
+			continue
 		}
 
 		log.Printf("writing file %s", job.output)
 
 		// write features
-		out, err := os.Create(job.output)
+		out, err := createOutput(job.output)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "could not write file %s: %s\n", job.output, err.Error())
-			return
+			report(job, "io", fmt.Sprintf("could not write file %s", job.output), err)
+			continue
 		}
 
 		err = output.WriteFeatures(stat, out, oconf)
 		out.Close()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error while writing features: %s\n", err.Error())
-			return
+			report(job, "io", "error while writing features", err)
+			continue
 		}
 	}
 
 	return
 }
 
+// analyze parses in according to the input format of job
+// and evaluates its features into stat
+func analyze(job work, in io.Reader, stat *output.Stats, pconf *input.ParsingConfig, fconf *stats.FeatureConfig) error {
+	switch inputFormat(job.name()) {
+	case ".wcnf":
+		wcnf, err := input.ReadWCNFFile(in, pconf)
+		if err != nil {
+			return err
+		}
+		err = evaluateHeader[sat.Lit](pconf.Report, occuringVariables(wcnf.CNF.Lits), nil, &stat.Fts)
+		if err != nil {
+			return err
+		}
+		return evaluateWCNF(wcnf, stat, fconf)
+	case ".qdimacs":
+		qbf, err := input.ReadQDIMACSFile(in, pconf)
+		if err != nil {
+			return err
+		}
+		err = evaluateHeader[sat.Lit](pconf.Report, occuringVariables(qbf.Matrix.Lits), nil, &stat.Fts)
+		if err != nil {
+			return err
+		}
+		return evaluateQBF(qbf, stat, fconf)
+	case ".icnf":
+		icnf, err := input.ReadICNFFile(in, pconf)
+		if err != nil {
+			return err
+		}
+		err = evaluateHeader[sat.Lit](pconf.Report, occuringVariables(icnf.CNF.Lits), nil, &stat.Fts)
+		if err != nil {
+			return err
+		}
+		return evaluateICNF(icnf, stat, fconf)
+	case ".opb":
+		pbf, err := input.ReadOPBFile(in, pconf)
+		if err != nil {
+			return err
+		}
+		err = evaluateHeader[sat.Lit](pconf.Report, pbVariables(pbf), nil, &stat.Fts)
+		if err != nil {
+			return err
+		}
+		return evaluatePB(pbf, stat, fconf)
+	}
+
+	if job.stream {
+		streamEval := newStreamEvaluator(&stat.Fts)
+		cnf, err := input.StreamCNFFile(in, pconf, streamEval.Clause)
+		if err != nil {
+			return err
+		}
+		err = evaluateHeader(pconf.Report, streamEval.occuringVariables(), cnf.XORs, &stat.Fts)
+		if err != nil {
+			return err
+		}
+		if len(cnf.XORs) > 0 {
+			stat.FtsXOR = output.NewXORFeatures()
+			err = evaluateXORs(cnf.XORs, streamEval.occuringVariables(), stat.FtsXOR)
+			if err != nil {
+				return err
+			}
+			if job.expandXORs {
+				err = cnf.ExpandXORs(xorCutLength, streamEval.Clause)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		stat.Approximate = approximateFeatures
+		return streamEval.Finish(cnf)
+	}
+
+	if job.wide {
+		cnf, err := input.ReadWideCNFFile(in, pconf)
+		if err != nil {
+			return err
+		}
+		return evaluateCNF(job, cnf, stat, pconf, fconf)
+	}
+
+	var cnf *sat.CNF
+	var err error
+	if job.parseUnits > 1 {
+		cnf, err = input.ReadCNFFileParallel(in, pconf, job.parseUnits)
+	} else {
+		cnf, err = input.ReadCNFFile(in, pconf)
+	}
+	if err != nil {
+		return err
+	}
+	return evaluateCNF(job, cnf, stat, pconf, fconf)
+}
+
+// evaluateCNF evaluates the features of a DIMACS CNF file into stat
+// once it has been parsed entirely
+func evaluateCNF[L sat.Literal](job work, cnf *sat.Formula[L], stat *output.Stats, pconf *input.ParsingConfig, fconf *stats.FeatureConfig) error {
+	occurs := occuringVariables(cnf.Lits)
+	err := evaluateHeader(pconf.Report, occurs, cnf.XORs, &stat.Fts)
+	if err != nil {
+		return err
+	}
+	if len(cnf.XORs) > 0 {
+		stat.FtsXOR = output.NewXORFeatures()
+		err = evaluateXORs(cnf.XORs, occurs, stat.FtsXOR)
+		if err != nil {
+			return err
+		}
+		if job.expandXORs {
+			err = cnf.ExpandXORs(xorCutLength, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	err = evaluate(cnf, &stat.Fts, fconf)
+	if err != nil {
+		return err
+	}
+	if job.simplify {
+		err = evaluateSimplified(cnf, stat, fconf)
+		if err != nil {
+			return err
+		}
+	}
+	if job.solve != nil {
+		evaluateSolving(cnf, stat, job.solve)
+	}
+	if job.probe != nil {
+		evaluateProbing(cnf, stat, job.probe)
+	}
+	return nil
+}
+
 func exists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
 
+// file extensions of compressed files, which are stripped in addition
+// to the actual file extension (foo.cnf.gz becomes foo)
+var compressionExts = map[string]bool{".gz": true, ".bz2": true, ".xz": true}
+
+// inputFormat returns the file extension of path which determines
+// the input format. Extensions of compressed files are skipped.
+func inputFormat(path string) string {
+	ext := filepath.Ext(path)
+	if compressionExts[ext] {
+		ext = filepath.Ext(path[0 : len(path)-len(ext)])
+	}
+	return ext
+}
+
+// stripExt removes the file extension of path. Extensions of
+// compressed files are removed in addition (foo.cnf.gz becomes foo).
+func stripExt(path string) string {
+	ext := filepath.Ext(path)
+	woExt := path[0 : len(path)-len(ext)]
+	if compressionExts[ext] {
+		ext = filepath.Ext(woExt)
+		woExt = woExt[0 : len(woExt)-len(ext)]
+	}
+	return woExt
+}
+
+// memberFormats lists the input formats of archive members considered
+var memberFormats = map[string]bool{".cnf": true, ".dimacs": true, ".wcnf": true, ".qdimacs": true, ".icnf": true, ".opb": true}
+
+func isMemberFormat(name string) bool {
+	return memberFormats[inputFormat(name)]
+}
+
+// dispatchArchive passes every member of the archive given by job to
+// the workers. If outputDir is empty, the stats of all members are
+// collected in the returned collection. Otherwise every member is
+// written to a file in outputDir/archive/ mirroring its path.
+func dispatchArchive(job work, outputDir string, skipExisting bool, workDist chan work) (*collection, error) {
+	var coll *collection
+	if outputDir == "" {
+		out, err := deriveFilePath(job.input, skipExisting)
+		if err != nil {
+			return nil, err
+		}
+		if out == "" {
+			fmt.Fprintf(os.Stderr, "%s was processed previously - skipping\n", job.input)
+			return nil, nil
+		}
+		coll = &collection{output: out}
+	}
+
+	err := input.WalkArchive(job.input, isMemberFormat, func(m *input.Member) error {
+		mjob := job
+		mjob.member = m.Name
+		mjob.data = m.Data
+		if m.Err != nil {
+			report(mjob, "archive", fmt.Sprintf("could not read archive member %s", mjob.name()), m.Err)
+			return nil
+		}
+		if coll != nil {
+			mjob.collected = coll
+			mjob.index = coll.add()
+		} else {
+			// cleaning the rooted path prevents escaping outputDir
+			base := filepath.Join(outputDir, filepath.Base(stripExt(job.input)), filepath.Clean("/"+m.Name))
+			out, err := deriveFilePath(base, skipExisting)
+			if err != nil {
+				return err
+			}
+			if out == "" {
+				fmt.Fprintf(os.Stderr, "%s was processed previously - skipping\n", mjob.name())
+				return nil
+			}
+			err = os.MkdirAll(filepath.Dir(out), 0755)
+			if err != nil {
+				return err
+			}
+			mjob.output = out
+		}
+		workDist <- mjob
+		return nil
+	})
+	return coll, err
+}
+
 func deriveFilePath(base string, skipExisting bool) (string, error) {
-	ext := filepath.Ext(base)
-	woExt := base[0 : len(base)-len(ext)]
+	woExt := stripExt(base)
 	newFile := woExt + ".stats.json"
 
 	if !exists(newFile) {
@@ -147,11 +612,32 @@ func deriveFilePath(base string, skipExisting bool) (string, error) {
 func main() {
 	var files []string
 	var ignoreLines []string
+	var includes []string
+	var excludes []string
 	format := output.JSONFormat
 	units := 4
+	parseUnits := 1
 	skip_existing := false
 	fullpath := false
 	hashes := true
+	digests := stats.DefaultDigests
+	stream := false
+	wide := false
+	jsonErrors := false
+	lenient := false
+	expandXORs := false
+	strict := false
+	comments := false
+	simplify := false
+	solve := false
+	budget := solver.NewBudget()
+	budget.Conflicts = 100000
+	budget.Time = 10 * time.Second
+	probe := false
+	probeConfig := solver.NewProbeConfig()
+	stdinOutput := "-"
+	stdinOutputGiven := false
+	outputDir := ""
 
 	skip := true
 	for i, arg := range os.Args {
@@ -186,40 +672,157 @@ func main() {
 			}
 			units = u
 			skip = true
+		} else if arg == "-P" || arg == "--parse-units" {
+			u, err := strconv.Atoi(os.Args[i+1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "--parse-units parameter invalid: %s\n", err.Error())
+				os.Exit(1)
+			} else if u <= 0 {
+				fmt.Fprintf(os.Stderr, "--parse-units must be positive\n")
+				os.Exit(1)
+			}
+			parseUnits = u
+			skip = true
 		} else if arg == "-n" || arg == "--no-hashes" {
 			hashes = false
+		} else if arg == "--digests" {
+			digests = strings.Split(os.Args[i+1], ",")
+			for _, name := range digests {
+				if !stats.ValidDigest(name) {
+					fmt.Fprintf(os.Stderr, "invalid digest supplied: '%s'\n", name)
+					os.Exit(1)
+				}
+			}
+			skip = true
 		} else if arg == "-p" || arg == "--fullpath" {
 			fullpath = true
 		} else if arg == "-s" || arg == "--skip-existing" {
 			skip_existing = true
+		} else if arg == "-o" || arg == "--output" {
+			stdinOutput = os.Args[i+1]
+			stdinOutputGiven = true
+			skip = true
+		} else if arg == "--output-dir" {
+			outputDir = os.Args[i+1]
+			skip = true
+		} else if arg == "--include" {
+			includes = append(includes, os.Args[i+1])
+			skip = true
+		} else if arg == "--exclude" {
+			excludes = append(excludes, os.Args[i+1])
+			skip = true
+		} else if arg == "--stream" {
+			stream = true
+		} else if arg == "--wide" {
+			wide = true
+		} else if arg == "--json-errors" {
+			jsonErrors = true
+		} else if arg == "--lenient" {
+			lenient = true
+		} else if arg == "--strict" {
+			strict = true
+		} else if arg == "--expand-xors" {
+			expandXORs = true
+		} else if arg == "--comments" {
+			comments = true
+		} else if arg == "--simplify" {
+			simplify = true
+		} else if arg == "--solve" {
+			solve = true
+		} else if arg == "--solve-conflicts" {
+			c, err := strconv.Atoi(os.Args[i+1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "--solve-conflicts parameter invalid: %s\n", err.Error())
+				os.Exit(1)
+			} else if c < 0 {
+				fmt.Fprintf(os.Stderr, "--solve-conflicts must not be negative\n")
+				os.Exit(1)
+			}
+			budget.Conflicts = c
+			solve = true
+			skip = true
+		} else if arg == "--solve-time" {
+			t, err := strconv.ParseFloat(os.Args[i+1], 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "--solve-time parameter invalid: %s\n", err.Error())
+				os.Exit(1)
+			} else if t < 0 {
+				fmt.Fprintf(os.Stderr, "--solve-time must not be negative\n")
+				os.Exit(1)
+			}
+			budget.Time = time.Duration(t * float64(time.Second))
+			solve = true
+			skip = true
+		} else if arg == "--probe" {
+			probe = true
+		} else if arg == "--probe-seed" {
+			seed, err := strconv.ParseInt(os.Args[i+1], 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "--probe-seed parameter invalid: %s\n", err.Error())
+				os.Exit(1)
+			}
+			probeConfig.Seed = seed
+			probe = true
+			skip = true
 		} else {
 			files = append(files, arg)
 		}
 	}
 
-	if len(files) < units {
-		units = len(files)
+	if strict && lenient {
+		fmt.Fprint(os.Stderr, "--strict and --lenient cannot be combined\n")
+		os.Exit(1)
+	}
+	if stream && parseUnits > 1 {
+		fmt.Fprint(os.Stderr, "--stream and --parse-units cannot be combined\n")
+		os.Exit(1)
+	}
+	if simplify && stream {
+		fmt.Fprint(os.Stderr, "--simplify and --stream cannot be combined\n")
+		os.Exit(1)
+	}
+	if solve && stream {
+		fmt.Fprint(os.Stderr, "--solve and --stream cannot be combined\n")
+		os.Exit(1)
+	}
+	if probe && stream {
+		fmt.Fprint(os.Stderr, "--probe and --stream cannot be combined\n")
+		os.Exit(1)
+	}
+	if stdinOutputGiven && !slices.Contains(files, "-") {
+		fmt.Fprint(os.Stderr, "--output applies to stdin only, but '-' is not among the inputs\n")
+		os.Exit(1)
+	}
+	if !solve {
+		budget = nil
+	}
+	if !probe {
+		probeConfig = nil
+	}
+	if wide && (stream || parseUnits > 1) {
+		fmt.Fprint(os.Stderr, "--wide cannot be combined with --stream or --parse-units\n")
+		os.Exit(1)
 	}
 
-	var jobs []work
+	err := validPatterns(includes)
+	if err == nil {
+		err = validPatterns(excludes)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	// the number of files is known in advance for plain files only
+	plain := true
 	for _, file := range files {
-		out, err := deriveFilePath(file, skip_existing)
-		if err != nil {
-			fmt.Fprint(os.Stderr, err.Error())
-			os.Exit(1)
-		}
-		if out == "" {
-			fmt.Fprintf(os.Stderr, "%s was processed previously - skipping\n", file)
-			continue
+		info, err := os.Stat(file)
+		if strings.HasPrefix(file, "@") || input.ArchiveFormat(file) != input.NoArchive || (err == nil && info.IsDir()) {
+			plain = false
 		}
-		jobs = append(jobs, work{
-			input:       file,
-			output:      out,
-			format:      format,
-			ignoreLines: ignoreLines,
-			fullpath:    fullpath,
-			hashes:      hashes,
-		})
+	}
+	if len(files) < units && plain {
+		units = len(files)
 	}
 
 	var w sync.WaitGroup
@@ -228,9 +831,44 @@ func main() {
 		w.Add(1)
 		go worker(workDist, &w)
 	}
-	for _, j := range jobs {
-		workDist <- j
+
+	d := dispatcher{
+		proto: work{
+			format:      format,
+			ignoreLines: ignoreLines,
+			fullpath:    fullpath,
+			hashes:      hashes,
+			digests:     digests,
+			parseUnits:  parseUnits,
+			stream:      stream,
+			wide:        wide,
+			jsonErrors:  jsonErrors,
+			lenient:     lenient,
+			strict:      strict,
+			expandXORs:  expandXORs,
+			comments:    comments,
+			simplify:    simplify,
+			solve:       budget,
+			probe:       probeConfig,
+		},
+		workDist:     workDist,
+		outputDir:    outputDir,
+		stdinOutput:  stdinOutput,
+		skipExisting: skip_existing,
+		includes:     includes,
+		excludes:     excludes,
+		visited:      make(map[string]bool),
+	}
+	for _, file := range files {
+		d.dispatch(file)
 	}
 	close(workDist)
 	w.Wait()
+
+	for _, coll := range d.collections {
+		err := coll.write(format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write file %s: %s\n", coll.output, err.Error())
+		}
+	}
 }
@@ -15,3 +15,30 @@ func WriteFeatures(data *Stats, out io.Writer, oconf *OutputConfig) error {
 	out.Write([]byte("]\n"))
 	return nil
 }
+
+// WriteFeaturesList writes the stats of several files as one JSON array
+func WriteFeaturesList(data []*Stats, out io.Writer, oconf *OutputConfig) error {
+	out.Write([]byte("["))
+	for i, stat := range data {
+		by, err := json.Marshal(stat)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			out.Write([]byte(",\n"))
+		}
+		out.Write(by)
+	}
+	_, err := out.Write([]byte("]\n"))
+	return err
+}
+
+// WriteDiagnostic writes d as JSON object in one line
+func WriteDiagnostic(d *Diagnostic, out io.Writer) error {
+	by, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(append(by, '\n'))
+	return err
+}
@@ -1,55 +1,249 @@
 package output
 
 type Stats struct {
-	CNFHash   string   `json:"@cnfhash"`
-	Filename  string   `json:"@filename"`
-	MD5Sum    string   `json:"@md5sum"`
-	SHA1Sum   string   `json:"@sha1sum"`
-	Timestamp string   `json:"@timestamp"`
-	Version   string   `json:"@version"`
-	Fts       Features `json:"featuring"`
+	Approximate          []string                `json:"@approximate,omitempty"`
+	BLAKE2bSum           string                  `json:"@blake2bsum,omitempty"`
+	CNFHash              string                  `json:"@cnfhash,omitempty"`
+	Comments             *Comments               `json:"@comments,omitempty"`
+	CompressedBLAKE2bSum string                  `json:"@compressed_blake2bsum,omitempty"`
+	CompressedMD5Sum     string                  `json:"@compressed_md5sum,omitempty"`
+	CompressedSHA1Sum    string                  `json:"@compressed_sha1sum,omitempty"`
+	CompressedSHA256Sum  string                  `json:"@compressed_sha256sum,omitempty"`
+	Compression          string                  `json:"@compression,omitempty"`
+	Diagnostics          []Diagnostic            `json:"@diagnostics,omitempty"`
+	Filename             string                  `json:"@filename"`
+	MD5Sum               string                  `json:"@md5sum,omitempty"`
+	Probing              *ProbingFeatures        `json:"probing,omitempty"`
+	SHA1Sum              string                  `json:"@sha1sum,omitempty"`
+	SHA256Sum            string                  `json:"@sha256sum,omitempty"`
+	Simplification       *SimplificationFeatures `json:"simplification,omitempty"`
+	Solving              *SolvingFeatures        `json:"solving,omitempty"`
+	Timestamp            string                  `json:"@timestamp"`
+	Version              string                  `json:"@version"`
+	Fts                  Features                `json:"featuring"`
+	FtsHard              *Features               `json:"featuring_hard,omitempty"`
+	FtsPB                *PBFeatures             `json:"featuring_pb,omitempty"`
+	FtsQBF               *QBFFeatures            `json:"featuring_qbf,omitempty"`
+	FtsSimplified        *Features               `json:"featuring_simplified,omitempty"`
+	FtsSoft              *Features               `json:"featuring_soft,omitempty"`
+	FtsXOR               *XORFeatures            `json:"featuring_xor,omitempty"`
+	Increments           []*IncrementFeatures    `json:"increments,omitempty"`
+	Weights              *WeightFeatures         `json:"weights,omitempty"`
 }
 
 func NewStats() *Stats {
 	return new(Stats)
 }
 
+// IncrementFeatures describe a solver call of an incremental CNF.
+// Fts refers to all clauses given until the call.
+type IncrementFeatures struct {
+	AddedClausesCount           uint64   `json:"added_clauses_count"`
+	AssumptionsCount            uint64   `json:"assumptions_count"`
+	NegativeAssumptionsCount    uint64   `json:"negative_assumptions_count"`
+	PositiveAssumptionsCount    uint64   `json:"positive_assumptions_count"`
+	PositiveAssumptionsFraction float64  `json:"positive_assumptions_fraction"`
+	Fts                         Features `json:"featuring"`
+}
+
+func NewIncrementFeatures() *IncrementFeatures {
+	return new(IncrementFeatures)
+}
+
+// PBFeatures describe the constraints of a pseudo-Boolean formula.
+// Coefficients and degrees refer to constraints normalized to ">="
+// with positive coefficients.
+type PBFeatures struct {
+	CardinalityConstraintsCount  uint64  `json:"cardinality_constraints_count"`
+	ClausalConstraintsCount      uint64  `json:"clausal_constraints_count"`
+	CoefficientsDistinctCount    uint64  `json:"coefficients_distinct_count"`
+	CoefficientsEntropy          float64 `json:"coefficients_entropy"`
+	CoefficientsLargest          int64   `json:"coefficients_largest"`
+	CoefficientsMean             float64 `json:"coefficients_mean"`
+	CoefficientsSd               float64 `json:"coefficients_sd"`
+	CoefficientsSmallest         int64   `json:"coefficients_smallest"`
+	ConnectedComponentsCount     uint64  `json:"connected_components_count"`
+	ConstraintsCount             uint64  `json:"constraints_count"`
+	ConstraintsLengthLargest     uint64  `json:"constraints_length_largest"`
+	ConstraintsLengthMean        float64 `json:"constraints_length_mean"`
+	ConstraintsLengthSd          float64 `json:"constraints_length_sd"`
+	ConstraintsLengthSmallest    uint64  `json:"constraints_length_smallest"`
+	DegreeLargest                int64   `json:"degree_largest"`
+	DegreeMean                   float64 `json:"degree_mean"`
+	DegreeSd                     float64 `json:"degree_sd"`
+	DegreeSmallest               int64   `json:"degree_smallest"`
+	EqualityConstraintsCount     uint64  `json:"equality_constraints_count"`
+	GeneralConstraintsCount      uint64  `json:"general_constraints_count"`
+	GreaterEqualConstraintsCount uint64  `json:"greater_equal_constraints_count"`
+	LessEqualConstraintsCount    uint64  `json:"less_equal_constraints_count"`
+	NbConstraints                uint64  `json:"nbconstraints"`
+	NbVars                       uint64  `json:"nbvars"`
+	ObjectiveTermsCount          uint64  `json:"objective_terms_count"`
+	TrivialConstraintsCount      uint64  `json:"trivial_constraints_count"`
+}
+
+func NewPBFeatures() *PBFeatures {
+	return new(PBFeatures)
+}
+
+// QBFFeatures describe the quantifier prefix of a QBF
+type QBFFeatures struct {
+	AlternationsCount             uint64  `json:"alternations_count"`
+	BlocksCount                   uint64  `json:"blocks_count"`
+	BlocksSizeLargest             uint64  `json:"blocks_size_largest"`
+	BlocksSizeMean                float64 `json:"blocks_size_mean"`
+	BlocksSizeSd                  float64 `json:"blocks_size_sd"`
+	BlocksSizeSmallest            uint64  `json:"blocks_size_smallest"`
+	ClausesWithUniversalsCount    uint64  `json:"clauses_with_universals_count"`
+	ExistentialVariablesCount     uint64  `json:"existential_variables_count"`
+	FreeVariablesCount            uint64  `json:"free_variables_count"`
+	InnermostBlockClausesCount    uint64  `json:"innermost_block_clauses_count"`
+	InnermostBlockQuantifier      string  `json:"innermost_block_quantifier"`
+	InnermostBlockSize            uint64  `json:"innermost_block_size"`
+	OutermostBlockClausesCount    uint64  `json:"outermost_block_clauses_count"`
+	OutermostBlockQuantifier      string  `json:"outermost_block_quantifier"`
+	OutermostBlockSize            uint64  `json:"outermost_block_size"`
+	UniversalLiteralsInClauseMean float64 `json:"universal_literals_in_clause_mean"`
+	UniversalVariablesCount       uint64  `json:"universal_variables_count"`
+	UniversalVariablesFraction    float64 `json:"universal_variables_fraction"`
+}
+
+func NewQBFFeatures() *QBFFeatures {
+	return new(QBFFeatures)
+}
+
+// WeightFeatures describe the weights of soft clauses in a WCNF
+type WeightFeatures struct {
+	HardClausesCount     uint64  `json:"hard_clauses_count"`
+	SoftClausesCount     uint64  `json:"soft_clauses_count"`
+	Top                  uint64  `json:"top"`
+	WeightsDistinctCount uint64  `json:"weights_distinct_count"`
+	WeightsEntropy       float64 `json:"weights_entropy"`
+	WeightsLargest       uint64  `json:"weights_largest"`
+	WeightsMean          float64 `json:"weights_mean"`
+	WeightsSd            float64 `json:"weights_sd"`
+	WeightsSmallest      uint64  `json:"weights_smallest"`
+}
+
+func NewWeightFeatures() *WeightFeatures {
+	return new(WeightFeatures)
+}
+
+// ProbingFeatures describe random DPLL probes as in SATzilla: the
+// literals assigned by unit propagation after 1 to 256 random decisions
+// and an estimate of the size of the search tree
+type ProbingFeatures struct {
+	ProbeDepthMean           float64 `json:"probe_depth_mean"`
+	ProbesCount              uint64  `json:"probes_count"`
+	SearchSpaceLog2          float64 `json:"search_space_log2"`
+	UnitPropagationsDepth1   uint64  `json:"unit_propagations_depth_1"`
+	UnitPropagationsDepth4   uint64  `json:"unit_propagations_depth_4"`
+	UnitPropagationsDepth16  uint64  `json:"unit_propagations_depth_16"`
+	UnitPropagationsDepth64  uint64  `json:"unit_propagations_depth_64"`
+	UnitPropagationsDepth256 uint64  `json:"unit_propagations_depth_256"`
+}
+
+func NewProbingFeatures() *ProbingFeatures {
+	return new(ProbingFeatures)
+}
+
+// SimplificationFeatures count what each simplification step removed
+// before the features of the simplified formula were evaluated
+type SimplificationFeatures struct {
+	Conflict                 bool   `json:"conflict"`
+	DuplicateLiteralsRemoved uint64 `json:"duplicate_literals_removed"`
+	FalsifiedLiteralsRemoved uint64 `json:"falsified_literals_removed"`
+	PureClausesRemoved       uint64 `json:"pure_clauses_removed"`
+	PureLiteralsAssigned     uint64 `json:"pure_literals_assigned"`
+	SatisfiedClausesRemoved  uint64 `json:"satisfied_clauses_removed"`
+	SubsumedClausesRemoved   uint64 `json:"subsumed_clauses_removed"`
+	TautologiesRemoved       uint64 `json:"tautologies_removed"`
+	UnitsAssigned            uint64 `json:"units_assigned"`
+}
+
+func NewSimplificationFeatures() *SimplificationFeatures {
+	return new(SimplificationFeatures)
+}
+
+// SolvingFeatures describe the answer of the embedded CDCL solver
+// and its effort. SatStatus is UNKNOWN if the budget was exhausted.
+type SolvingFeatures struct {
+	ConflictsCount             uint64  `json:"conflicts_count"`
+	DecisionsCount             uint64  `json:"decisions_count"`
+	DeletedClausesCount        uint64  `json:"deleted_clauses_count"`
+	LearntClausesCount         uint64  `json:"learnt_clauses_count"`
+	LearntClausesLBDMean       float64 `json:"learnt_clauses_lbd_mean"`
+	LearntClausesLengthLargest uint64  `json:"learnt_clauses_length_largest"`
+	LearntClausesLengthMean    float64 `json:"learnt_clauses_length_mean"`
+	LearntUnitClausesCount     uint64  `json:"learnt_unit_clauses_count"`
+	PropagationsCount          uint64  `json:"propagations_count"`
+	RestartsCount              uint64  `json:"restarts_count"`
+	SatStatus                  string  `json:"sat_status"`
+	SolvingTime                float64 `json:"solving_time"`
+}
+
+func NewSolvingFeatures() *SolvingFeatures {
+	return new(SolvingFeatures)
+}
+
+// XORFeatures describe the XOR constraints of a CNF
+type XORFeatures struct {
+	SharedVariablesCount    uint64  `json:"shared_variables_count"`
+	SharedVariablesFraction float64 `json:"shared_variables_fraction"`
+	VariablesCount          uint64  `json:"variables_count"`
+	XORsCount               uint64  `json:"xors_count"`
+	XORsLengthLargest       uint32  `json:"xors_length_largest"`
+	XORsLengthMean          float64 `json:"xors_length_mean"`
+	XORsLengthMedian        float64 `json:"xors_length_median"`
+	XORsLengthSd            float64 `json:"xors_length_sd"`
+	XORsLengthSmallest      uint32  `json:"xors_length_smallest"`
+	XORsRank                int64   `json:"xors_rank"`
+}
+
+func NewXORFeatures() *XORFeatures {
+	return new(XORFeatures)
+}
+
 type Features struct {
+	ActualClausesCount                           uint64  `json:"actual_clauses_count"`
 	ClauseVariablesSdMean                        float64 `json:"clause_variables_sd_mean"`
-	ClausesCount                                 uint32  `json:"clauses_count"`
-	ClausesLengthLargest                         uint16  `json:"clauses_length_largest"`
+	ClausesCount                                 uint64  `json:"clauses_count"`
+	ClausesLengthLargest                         uint32  `json:"clauses_length_largest"`
 	ClausesLengthMean                            float64 `json:"clauses_length_mean"`
 	ClausesLengthMedian                          float64 `json:"clauses_length_median"`
 	ClausesLengthSd                              float64 `json:"clauses_length_sd"`
-	ClausesLengthSmallest                        uint16  `json:"clauses_length_smallest"`
-	ConnectedLiteralComponentsCount              uint16  `json:"connected_literal_components_count"`
-	ConnectedVariableComponentsCount             uint16  `json:"connected_variable_components_count"`
-	DefiniteClausesCount                         uint32  `json:"definite_clauses_count"`
-	ExistentialLiteralsCount                     uint32  `json:"existential_literals_count"`
-	ExistentialPositiveLiteralsCount             uint32  `json:"existential_positive_literals_count"`
+	ClausesLengthSmallest                        uint32  `json:"clauses_length_smallest"`
+	ConnectedLiteralComponentsCount              uint64  `json:"connected_literal_components_count"`
+	ConnectedVariableComponentsCount             uint64  `json:"connected_variable_components_count"`
+	DefiniteClausesCount                         uint64  `json:"definite_clauses_count"`
+	EmptyClauseCount                             uint64  `json:"empty_clause_count"`
+	ExistentialLiteralsCount                     uint64  `json:"existential_literals_count"`
+	ExistentialPositiveLiteralsCount             uint64  `json:"existential_positive_literals_count"`
 	FalseTrivial                                 bool    `json:"false_trivial"`
-	GoalClausesCount                             uint32  `json:"goal_clauses_count"`
+	GoalClausesCount                             uint64  `json:"goal_clauses_count"`
+	HeaderNbClausesMismatch                      bool    `json:"header_nbclauses_mismatch"`
+	HeaderNbVarsMismatch                         bool    `json:"header_nbvars_mismatch"`
 	LiteralsCount                                uint64  `json:"literals_count"`
-	LiteralsFrequency0To5                        uint32  `json:"literals_frequency_0_to_5"`
-	LiteralsFrequency5To10                       uint32  `json:"literals_frequency_5_to_10"`
-	LiteralsFrequency10To15                      uint32  `json:"literals_frequency_10_to_15"`
-	LiteralsFrequency15To20                      uint32  `json:"literals_frequency_15_to_20"`
-	LiteralsFrequency20To25                      uint32  `json:"literals_frequency_20_to_25"`
-	LiteralsFrequency25To30                      uint32  `json:"literals_frequency_25_to_30"`
-	LiteralsFrequency30To35                      uint32  `json:"literals_frequency_30_to_35"`
-	LiteralsFrequency35To40                      uint32  `json:"literals_frequency_35_to_40"`
-	LiteralsFrequency40To45                      uint32  `json:"literals_frequency_40_to_45"`
-	LiteralsFrequency45To50                      uint32  `json:"literals_frequency_45_to_50"`
-	LiteralsFrequency50To55                      uint32  `json:"literals_frequency_50_to_55"`
-	LiteralsFrequency55To60                      uint32  `json:"literals_frequency_55_to_60"`
-	LiteralsFrequency60To65                      uint32  `json:"literals_frequency_60_to_65"`
-	LiteralsFrequency65To70                      uint32  `json:"literals_frequency_65_to_70"`
-	LiteralsFrequency70To75                      uint32  `json:"literals_frequency_70_to_75"`
-	LiteralsFrequency75To80                      uint32  `json:"literals_frequency_75_to_80"`
-	LiteralsFrequency80To85                      uint32  `json:"literals_frequency_80_to_85"`
-	LiteralsFrequency85To90                      uint32  `json:"literals_frequency_85_to_90"`
-	LiteralsFrequency90To95                      uint32  `json:"literals_frequency_90_to_95"`
-	LiteralsFrequency95To100                     uint32  `json:"literals_frequency_95_to_100"`
+	LiteralsFrequency0To5                        uint64  `json:"literals_frequency_0_to_5"`
+	LiteralsFrequency5To10                       uint64  `json:"literals_frequency_5_to_10"`
+	LiteralsFrequency10To15                      uint64  `json:"literals_frequency_10_to_15"`
+	LiteralsFrequency15To20                      uint64  `json:"literals_frequency_15_to_20"`
+	LiteralsFrequency20To25                      uint64  `json:"literals_frequency_20_to_25"`
+	LiteralsFrequency25To30                      uint64  `json:"literals_frequency_25_to_30"`
+	LiteralsFrequency30To35                      uint64  `json:"literals_frequency_30_to_35"`
+	LiteralsFrequency35To40                      uint64  `json:"literals_frequency_35_to_40"`
+	LiteralsFrequency40To45                      uint64  `json:"literals_frequency_40_to_45"`
+	LiteralsFrequency45To50                      uint64  `json:"literals_frequency_45_to_50"`
+	LiteralsFrequency50To55                      uint64  `json:"literals_frequency_50_to_55"`
+	LiteralsFrequency55To60                      uint64  `json:"literals_frequency_55_to_60"`
+	LiteralsFrequency60To65                      uint64  `json:"literals_frequency_60_to_65"`
+	LiteralsFrequency65To70                      uint64  `json:"literals_frequency_65_to_70"`
+	LiteralsFrequency70To75                      uint64  `json:"literals_frequency_70_to_75"`
+	LiteralsFrequency75To80                      uint64  `json:"literals_frequency_75_to_80"`
+	LiteralsFrequency80To85                      uint64  `json:"literals_frequency_80_to_85"`
+	LiteralsFrequency85To90                      uint64  `json:"literals_frequency_85_to_90"`
+	LiteralsFrequency90To95                      uint64  `json:"literals_frequency_90_to_95"`
+	LiteralsFrequency95To100                     uint64  `json:"literals_frequency_95_to_100"`
 	LiteralsFrequencyEntropy                     float64 `json:"literals_frequency_entropy"`
 	LiteralsFrequencyLargest                     float64 `json:"literals_frequency_largest"`
 	LiteralsFrequencyMean                        float64 `json:"literals_frequency_mean"`
@@ -57,56 +251,83 @@ type Features struct {
 	LiteralsFrequencySd                          float64 `json:"literals_frequency_sd"`
 	LiteralsFrequencySmallest                    float64 `json:"literals_frequency_smallest"`
 	LiteralsOccurenceOneCount                    uint64  `json:"literals_occurence_one_count"`
-	NbClauses                                    uint32  `json:"nbclauses"`
-	NbVars                                       uint32  `json:"nbvars"`
-	NegativeLiteralsInClauseLargest              uint16  `json:"negative_literals_in_clause_largest"`
+	MaxVariable                                  uint64  `json:"max_variable"`
+	NbClauses                                    uint64  `json:"nbclauses"`
+	NbVars                                       uint64  `json:"nbvars"`
+	NegativeLiteralsInClauseLargest              uint32  `json:"negative_literals_in_clause_largest"`
 	NegativeLiteralsInClauseMean                 float64 `json:"negative_literals_in_clause_mean"`
-	NegativeLiteralsInClauseSmallest             uint16  `json:"negative_literals_in_clause_smallest"`
-	NegativeUnitClauseCount                      uint32  `json:"negative_unit_clause_count"`
-	PositiveLiteralsCount                        uint32  `json:"positive_literals_count"`
-	PositiveLiteralsInClauseLargest              uint16  `json:"positive_literals_in_clause_largest"`
+	NegativeLiteralsInClauseSmallest             uint32  `json:"negative_literals_in_clause_smallest"`
+	NegativeUnitClauseCount                      uint64  `json:"negative_unit_clause_count"`
+	PositiveLiteralsCount                        uint64  `json:"positive_literals_count"`
+	PositiveLiteralsInClauseLargest              uint32  `json:"positive_literals_in_clause_largest"`
 	PositiveLiteralsInClauseMean                 float64 `json:"positive_literals_in_clause_mean"`
 	PositiveLiteralsInClauseMedian               float32 `json:"positive_literals_in_clause_median"`
 	PositiveLiteralsInClauseSd                   float64 `json:"positive_literals_in_clause_sd"`
-	PositiveLiteralsInClauseSmallest             uint16  `json:"positive_literals_in_clause_smallest"`
+	PositiveLiteralsInClauseSmallest             uint32  `json:"positive_literals_in_clause_smallest"`
 	PositiveNegativeLiteralsInClauseRatioEntropy float64 `json:"positive_negative_literals_in_clause_ratio_entropy"`
 	PositiveNegativeLiteralsInClauseRatioStdev   float64 `json:"positive_negative_literals_in_clause_ratio_stdev"`
 	PositiveNegativeLiteralsInClauseRatioMean    float64 `json:"positive_negative_literals_in_clause_ratio_mean"`
-	PositiveUnitClauseCount                      uint32  `json:"positive_unit_clause_count"`
-	TautologicalLiteralsCount                    uint16  `json:"tautological_literals_count"`
+	PositiveUnitClauseCount                      uint64  `json:"positive_unit_clause_count"`
+	TautologicalLiteralsCount                    uint64  `json:"tautological_literals_count"`
+	TriviallyUnsat                               bool    `json:"trivially_unsat"`
 	TrueTrivial                                  bool    `json:"true_trivial"`
-	TwoLiteralsClauseCount                       uint32  `json:"two_literals_clause_count"`
-	VariablesFrequency0To5                       uint32  `json:"variables_frequency_0_to_5"`
-	VariablesFrequency5To10                      uint32  `json:"variables_frequency_5_to_10"`
-	VariablesFrequency10To15                     uint32  `json:"variables_frequency_10_to_15"`
-	VariablesFrequency15To20                     uint32  `json:"variables_frequency_15_to_20"`
-	VariablesFrequency20To25                     uint32  `json:"variables_frequency_20_to_25"`
-	VariablesFrequency25To30                     uint32  `json:"variables_frequency_25_to_30"`
-	VariablesFrequency30To35                     uint32  `json:"variables_frequency_30_to_35"`
-	VariablesFrequency35To40                     uint32  `json:"variables_frequency_35_to_40"`
-	VariablesFrequency40To45                     uint32  `json:"variables_frequency_40_to_45"`
-	VariablesFrequency45To50                     uint32  `json:"variables_frequency_45_to_50"`
-	VariablesFrequency50To55                     uint32  `json:"variables_frequency_50_to_55"`
-	VariablesFrequency55To60                     uint32  `json:"variables_frequency_55_to_60"`
-	VariablesFrequency60To65                     uint32  `json:"variables_frequency_60_to_65"`
-	VariablesFrequency65To70                     uint32  `json:"variables_frequency_65_to_70"`
-	VariablesFrequency70To75                     uint32  `json:"variables_frequency_70_to_75"`
-	VariablesFrequency75To80                     uint32  `json:"variables_frequency_75_to_80"`
-	VariablesFrequency80To85                     uint32  `json:"variables_frequency_80_to_85"`
-	VariablesFrequency85To90                     uint32  `json:"variables_frequency_85_to_90"`
-	VariablesFrequency90To95                     uint32  `json:"variables_frequency_90_to_95"`
-	VariablesFrequency95To100                    uint32  `json:"variables_frequency_95_to_100"`
+	TwoLiteralsClauseCount                       uint64  `json:"two_literals_clause_count"`
+	UnusedDeclaredVariables                      uint64  `json:"unused_declared_variables"`
+	VariablesFrequency0To5                       uint64  `json:"variables_frequency_0_to_5"`
+	VariablesFrequency5To10                      uint64  `json:"variables_frequency_5_to_10"`
+	VariablesFrequency10To15                     uint64  `json:"variables_frequency_10_to_15"`
+	VariablesFrequency15To20                     uint64  `json:"variables_frequency_15_to_20"`
+	VariablesFrequency20To25                     uint64  `json:"variables_frequency_20_to_25"`
+	VariablesFrequency25To30                     uint64  `json:"variables_frequency_25_to_30"`
+	VariablesFrequency30To35                     uint64  `json:"variables_frequency_30_to_35"`
+	VariablesFrequency35To40                     uint64  `json:"variables_frequency_35_to_40"`
+	VariablesFrequency40To45                     uint64  `json:"variables_frequency_40_to_45"`
+	VariablesFrequency45To50                     uint64  `json:"variables_frequency_45_to_50"`
+	VariablesFrequency50To55                     uint64  `json:"variables_frequency_50_to_55"`
+	VariablesFrequency55To60                     uint64  `json:"variables_frequency_55_to_60"`
+	VariablesFrequency60To65                     uint64  `json:"variables_frequency_60_to_65"`
+	VariablesFrequency65To70                     uint64  `json:"variables_frequency_65_to_70"`
+	VariablesFrequency70To75                     uint64  `json:"variables_frequency_70_to_75"`
+	VariablesFrequency75To80                     uint64  `json:"variables_frequency_75_to_80"`
+	VariablesFrequency80To85                     uint64  `json:"variables_frequency_80_to_85"`
+	VariablesFrequency85To90                     uint64  `json:"variables_frequency_85_to_90"`
+	VariablesFrequency90To95                     uint64  `json:"variables_frequency_90_to_95"`
+	VariablesFrequency95To100                    uint64  `json:"variables_frequency_95_to_100"`
 	VariablesFrequencyEntropy                    float64 `json:"variables_frequency_entropy"`
 	VariablesFrequencyLargest                    float64 `json:"variables_frequency_largest"`
 	VariablesFrequencyMean                       float64 `json:"variables_frequency_mean"`
 	VariablesFrequencyMedian                     float64 `json:"variables_frequency_median"`
 	VariablesFrequencySd                         float64 `json:"variables_frequency_sd"`
 	VariablesFrequencySmallest                   float64 `json:"variables_frequency_smallest"`
-	VariablesLargest                             uint32  `json:"variables_largest"`
-	VariablesSmallest                            uint32  `json:"variables_smallest"`
-	VariablesUsedCount                           uint32  `json:"variables_used_count"`
+	VariablesLargest                             uint64  `json:"variables_largest"`
+	VariablesSmallest                            uint64  `json:"variables_smallest"`
+	VariablesUsedCount                           uint64  `json:"variables_used_count"`
 }
 
 func NewFeatures() *Features {
 	return new(Features)
 }
+
+// Comments describe the comment lines of an input file. Values maps
+// the keys of "key: value" and "key=value" pairs to their first value.
+type Comments struct {
+	BytesCount uint64            `json:"bytes_count"`
+	LinesCount uint64            `json:"lines_count"`
+	Values     map[string]string `json:"values"`
+}
+
+func NewComments() *Comments {
+	c := new(Comments)
+	c.Values = make(map[string]string)
+	return c
+}
+
+// Diagnostic describes a problem with an input file in a machine-readable way
+type Diagnostic struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Col      int    `json:"col,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
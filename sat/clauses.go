@@ -0,0 +1,90 @@
+package sat
+
+import "iter"
+
+// SplitClauses iterates the zero-terminated clauses of lits with their
+// index. The yielded clauses exclude the terminating 0 and share memory
+// with lits. Literals after the last 0 are not yielded.
+func SplitClauses[L Literal](lits []L) iter.Seq2[int, []L] {
+	return func(yield func(int, []L) bool) {
+		i := 0
+		start := 0
+		for j, lit := range lits {
+			if lit != 0 {
+				continue
+			}
+			if !yield(i, lits[start:j]) {
+				return
+			}
+			i += 1
+			start = j + 1
+		}
+	}
+}
+
+// Clauses iterates the clauses of c, see SplitClauses
+func (c *Formula[L]) Clauses() iter.Seq2[int, []L] {
+	return SplitClauses(c.Lits)
+}
+
+// XORConstraints iterates the XOR constraints of c, see SplitClauses
+func (c *Formula[L]) XORConstraints() iter.Seq[[]L] {
+	return func(yield func([]L) bool) {
+		for _, xor := range SplitClauses(c.XORs) {
+			if !yield(xor) {
+				return
+			}
+		}
+	}
+}
+
+// Literals iterates the literals of all clauses of c
+// (without terminating zeros)
+func (c *Formula[L]) Literals() iter.Seq[L] {
+	return func(yield func(L) bool) {
+		for _, lit := range c.Lits {
+			if lit != 0 && !yield(lit) {
+				return
+			}
+		}
+	}
+}
+
+// Offsets returns the clause index of c in compressed sparse row
+// layout: clause i occupies Lits[offsets[i]:offsets[i+1]-1] and is
+// terminated by the 0 at offsets[i+1]-1. There is one entry more than
+// there are clauses.
+//
+// The index is built on first use and extended if clauses have been
+// appended to Lits since. If Lits is modified otherwise, the index
+// must be dropped with ResetOffsets. Building the index modifies c,
+// hence concurrent readers must call Offsets before sharing c.
+func (c *Formula[L]) Offsets() []int {
+	if len(c.offsets) == 0 || c.offsets[len(c.offsets)-1] > len(c.Lits) {
+		c.offsets = append(c.offsets[:0], 0)
+	}
+	for i := c.offsets[len(c.offsets)-1]; i < len(c.Lits); i++ {
+		if c.Lits[i] == 0 {
+			c.offsets = append(c.offsets, i+1)
+		}
+	}
+	return c.offsets
+}
+
+// ResetOffsets drops the clause index of c
+func (c *Formula[L]) ResetOffsets() {
+	c.offsets = nil
+}
+
+// NumClauses returns the number of clauses in Lits, which differs
+// from NbClauses if the header is wrong or Lits is not retained
+func (c *Formula[L]) NumClauses() int {
+	return len(c.Offsets()) - 1
+}
+
+// Clause returns the literals of clause i without the terminating 0.
+// The slice shares memory with Lits.
+func (c *Formula[L]) Clause(i int) []L {
+	offsets := c.Offsets()
+	return c.Lits[offsets[i] : offsets[i+1]-1]
+}
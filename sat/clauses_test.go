@@ -0,0 +1,40 @@
+package sat
+
+import (
+	"slices"
+	"testing"
+)
+
+func checkClauses(t *testing.T, cnf *CNF, want [][]Lit) {
+	t.Helper()
+	if cnf.NumClauses() != len(want) {
+		t.Fatalf("got %d clauses, want %d", cnf.NumClauses(), len(want))
+	}
+	for i, clause := range want {
+		if got := cnf.Clause(i); !slices.Equal(got, clause) {
+			t.Errorf("clause %d is %v, want %v", i, got, clause)
+		}
+	}
+}
+
+func TestOffsets(t *testing.T) {
+	cnf := NewCNF()
+	cnf.Lits = append(cnf.Lits, 1, -2, 0, 3, 0, 0, 4)
+	checkClauses(t, cnf, [][]Lit{{1, -2}, {3}, {}})
+	if got := cnf.Offsets(); !slices.Equal(got, []int{0, 3, 5, 6}) {
+		t.Errorf("offsets are %v", got)
+	}
+
+	// appending extends the index, including the unterminated clause
+	cnf.Lits = append(cnf.Lits, -5, 0, 2, 0)
+	checkClauses(t, cnf, [][]Lit{{1, -2}, {3}, {}, {4, -5}, {2}})
+
+	// other modifications require dropping the index
+	copy(cnf.Lits, []Lit{1, 0, 2, -2, 0})
+	cnf.ResetOffsets()
+	checkClauses(t, cnf, [][]Lit{{1}, {2, -2}, {}, {4, -5}, {2}})
+
+	// a shorter Lits is detected
+	cnf.Lits = cnf.Lits[:2]
+	checkClauses(t, cnf, [][]Lit{{1}})
+}
@@ -0,0 +1,225 @@
+package sat
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+)
+
+// WriteConfig selects the normalizations applied by WriteDIMACS.
+// They are applied to every clause in the order of the fields.
+type WriteConfig struct {
+	// SortLiterals sorts the literals of each clause by variable;
+	// a negative literal precedes the positive literal of its variable
+	SortLiterals bool
+	// DropDuplicateLiterals keeps only the first occurence of
+	// a literal within a clause
+	DropDuplicateLiterals bool
+	// DropTautologies omits clauses containing a literal
+	// and its complement
+	DropTautologies bool
+	// DropDuplicateClauses omits clauses equal to a previous clause.
+	// Clauses are compared as sequences, hence as sets of literals
+	// in combination with SortLiterals and DropDuplicateLiterals.
+	DropDuplicateClauses bool
+	// CompactVariables renumbers the variables occuring in the
+	// written clauses consecutively from 1 preserving their order
+	CompactVariables bool
+}
+
+func NewWriteConfig() *WriteConfig {
+	return new(WriteConfig)
+}
+
+// WriteDIMACS writes cnf in DIMACS format to w; a header with the
+// number of variables and clauses written followed by one clause per
+// line. XOR constraints are written after the clauses in the format of
+// CryptoMiniSat ("x1 -2 3 0") and counted as clauses in the header;
+// only CompactVariables applies to them. If conf is nil, the clauses
+// are written as they are.
+func WriteDIMACS[L Literal](w io.Writer, cnf *Formula[L], conf *WriteConfig) error {
+	if conf == nil {
+		conf = NewWriteConfig()
+	}
+
+	nbvars := cnf.VarCount()
+	if max := cnf.maxVar(); max > nbvars {
+		nbvars = max
+	}
+
+	// the header precedes the clauses, hence normalize them first
+	lits := cnf.Lits
+	if conf.SortLiterals || conf.DropDuplicateLiterals || conf.DropTautologies || conf.DropDuplicateClauses {
+		lits = normalizeClauses(lits, nbvars, conf)
+	}
+	nbclauses := 0
+	for _, ls := range [][]L{lits, cnf.XORs} {
+		for _, lit := range ls {
+			if lit == 0 {
+				nbclauses += 1
+			}
+		}
+	}
+	var rename func(L) L
+	if conf.CompactVariables {
+		rename, nbvars = compactVariables(lits, cnf.XORs, nbvars)
+	}
+
+	bw := bufio.NewWriter(w)
+	header := []byte("p cnf ")
+	header = strconv.AppendInt(header, int64(nbvars), 10)
+	header = append(header, ' ')
+	header = strconv.AppendInt(header, int64(nbclauses), 10)
+	header = append(header, '\n')
+	bw.Write(header)
+	writeClauses(bw, lits, "", rename)
+	writeClauses(bw, cnf.XORs, "x", rename)
+	// errors of bw are sticky and returned by Flush
+	return bw.Flush()
+}
+
+// Dump writes c in DIMACS format to stdout
+func (c *Formula[L]) Dump() error {
+	return WriteDIMACS(os.Stdout, c, nil)
+}
+
+func abs[L Literal](lit L) L {
+	if lit < 0 {
+		return -lit
+	}
+	return lit
+}
+
+// compareLits orders literals by variable, negative literals first
+func compareLits[L Literal](a, b L) int {
+	if c := cmp.Compare(abs(a), abs(b)); c != 0 {
+		return c
+	}
+	return cmp.Compare(a, b)
+}
+
+// normalizeClauses returns the zero-terminated clauses of lits after
+// applying the normalizations of conf. nbvars is the largest variable.
+func normalizeClauses[L Literal](lits []L, nbvars int, conf *WriteConfig) []L {
+	out := make([]L, 0, len(lits))
+
+	// polarities of the variables of the current clause;
+	// bit 1 for positive, bit 2 for negative occurences
+	var marks []uint8
+	if conf.DropDuplicateLiterals || conf.DropTautologies {
+		marks = make([]uint8, nbvars+1)
+	}
+	seen := make(map[string]bool)
+	key := make([]byte, 0, 64)
+
+	for _, clause := range SplitClauses(lits) {
+		start := len(out)
+		tautology := false
+		for _, lit := range clause {
+			if marks != nil {
+				v, bit := abs(lit), uint8(1)
+				if lit < 0 {
+					bit = 2
+				}
+				if conf.DropDuplicateLiterals && marks[v]&bit != 0 {
+					continue
+				}
+				marks[v] |= bit
+				tautology = tautology || marks[v] == 3
+			}
+			out = append(out, lit)
+		}
+		if marks != nil {
+			for _, lit := range out[start:] {
+				marks[abs(lit)] = 0
+			}
+		}
+
+		if conf.DropTautologies && tautology {
+			out = out[:start]
+			continue
+		}
+		if conf.SortLiterals {
+			slices.SortFunc(out[start:], compareLits)
+		}
+		if conf.DropDuplicateClauses {
+			key = key[:0]
+			for _, lit := range out[start:] {
+				key = binary.AppendVarint(key, int64(lit))
+			}
+			if seen[string(key)] {
+				out = out[:start]
+				continue
+			}
+			seen[string(key)] = true
+		}
+		out = append(out, 0)
+	}
+	return out
+}
+
+// compactVariables maps every variable occuring in lits or xors to its
+// rank among these variables and returns the number of these variables.
+// If variables up to nbvars are sparse, they are mapped by binary search
+// instead of a table indexed by all variables.
+func compactVariables[L Literal](lits, xors []L, nbvars int) (func(L) L, int) {
+	if nbvars <= len(lits)+len(xors) {
+		rename := make([]L, nbvars+1)
+		for _, ls := range [][]L{lits, xors} {
+			for _, lit := range ls {
+				if lit != 0 {
+					rename[abs(lit)] = 1
+				}
+			}
+		}
+		n := 0
+		for v := 1; v < len(rename); v++ {
+			if rename[v] != 0 {
+				n += 1
+				rename[v] = L(n)
+			}
+		}
+		return func(v L) L { return rename[v] }, n
+	}
+
+	vars := make([]L, 0)
+	for _, ls := range [][]L{lits, xors} {
+		for _, lit := range ls {
+			if lit != 0 {
+				vars = append(vars, abs(lit))
+			}
+		}
+	}
+	slices.Sort(vars)
+	vars = slices.Compact(vars)
+	return func(v L) L {
+		i, _ := slices.BinarySearch(vars, v)
+		return L(i + 1)
+	}, len(vars)
+}
+
+// writeClauses writes one line per zero-terminated clause of lits,
+// starting with prefix. Variables are renamed if rename is non-nil.
+func writeClauses[L Literal](w *bufio.Writer, lits []L, prefix string, rename func(L) L) {
+	line := make([]byte, 0, 64)
+	for _, clause := range SplitClauses(lits) {
+		line = append(line[:0], prefix...)
+		for _, lit := range clause {
+			if rename != nil {
+				if lit < 0 {
+					lit = -rename(-lit)
+				} else {
+					lit = rename(lit)
+				}
+			}
+			line = strconv.AppendInt(line, int64(lit), 10)
+			line = append(line, ' ')
+		}
+		line = append(line, '0', '\n')
+		w.Write(line)
+	}
+}
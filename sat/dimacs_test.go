@@ -0,0 +1,201 @@
+package sat_test
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/prokls/cnf-analysis-go/input"
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+// roundTrip writes cnf with conf and parses the output again
+func roundTrip(t *testing.T, cnf *sat.CNF, conf *sat.WriteConfig) *sat.CNF {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := sat.WriteDIMACS(&buf, cnf, conf); err != nil {
+		t.Fatal(err)
+	}
+	pconf := input.NewParsingConfig()
+	pconf.CheckNbVars = true
+	pconf.CheckNbClauses = true
+	read, err := input.ReadCNFFile(&buf, pconf)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, buf.String())
+	}
+	return read
+}
+
+// randomCNF returns clauses, including duplicate literals, tautologies
+// and empty clauses, and XOR constraints over nbvars variables
+func randomCNF(rng *rand.Rand, nbvars int) *sat.CNF {
+	literal := func() sat.Lit {
+		l := sat.Lit(rng.Intn(nbvars) + 1)
+		if rng.Intn(2) == 0 {
+			return -l
+		}
+		return l
+	}
+
+	cnf := sat.NewCNF()
+	cnf.NbVars = nbvars
+	for c := rng.Intn(20); c > 0; c-- {
+		for k := rng.Intn(5); k > 0; k-- {
+			cnf.Lits = append(cnf.Lits, literal())
+		}
+		cnf.Lits = append(cnf.Lits, 0)
+		cnf.NbClauses += 1
+	}
+	for x := rng.Intn(3); x > 0; x-- {
+		for k := rng.Intn(4) + 1; k > 0; k-- {
+			cnf.XORs = append(cnf.XORs, literal())
+		}
+		cnf.XORs = append(cnf.XORs, 0)
+		cnf.NbClauses += 1
+	}
+	return cnf
+}
+
+func TestWriteDIMACSRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		cnf := randomCNF(rng, rng.Intn(10)+1)
+		read := roundTrip(t, cnf, nil)
+		if read.NbVars != cnf.VarCount() || read.NbClauses != cnf.NbClauses ||
+			!slices.Equal(read.Lits, cnf.Lits) || !slices.Equal(read.XORs, cnf.XORs) {
+			t.Fatalf("wrote %d variables, %d clauses %v and XORs %v, read %d variables, %d clauses %v and XORs %v",
+				cnf.VarCount(), cnf.NbClauses, cnf.Lits, cnf.XORs,
+				read.NbVars, read.NbClauses, read.Lits, read.XORs)
+		}
+	}
+}
+
+func TestWriteDIMACSNormalized(t *testing.T) {
+	cnf := sat.NewCNF()
+	cnf.NbVars = 6
+	cnf.NbClauses = 5
+	cnf.Lits = []sat.Lit{3, -1, 3, 0, 1, -1, 2, 0, -1, 3, 0, 5, 0}
+	cnf.XORs = []sat.Lit{5, -3, 0}
+
+	conf := &sat.WriteConfig{
+		SortLiterals:          true,
+		DropDuplicateLiterals: true,
+		DropTautologies:       true,
+		DropDuplicateClauses:  true,
+		CompactVariables:      true,
+	}
+	read := roundTrip(t, cnf, conf)
+
+	// variables 1, 3 and 5 become 1, 2 and 3; variable 2 only occurs
+	// in a tautology
+	if read.NbVars != 3 || read.NbClauses != 3 ||
+		!slices.Equal(read.Lits, []sat.Lit{-1, 2, 0, 3, 0}) || !slices.Equal(read.XORs, []sat.Lit{3, -2, 0}) {
+		t.Errorf("read %d variables, %d clauses %v and XORs %v", read.NbVars, read.NbClauses, read.Lits, read.XORs)
+	}
+}
+
+// byVariable orders literals by variable, negative literals first
+func byVariable(a, b sat.Lit) int {
+	if c := cmp.Compare(max(a, -a), max(b, -b)); c != 0 {
+		return c
+	}
+	return cmp.Compare(a, b)
+}
+
+func TestWriteDIMACSRandomNormalized(t *testing.T) {
+	normalize := &sat.WriteConfig{
+		SortLiterals:          true,
+		DropDuplicateLiterals: true,
+		DropTautologies:       true,
+		DropDuplicateClauses:  true,
+	}
+	compact := *normalize
+	compact.CompactVariables = true
+
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 1000; i++ {
+		cnf := randomCNF(rng, rng.Intn(10)+1)
+		normalized := roundTrip(t, cnf, normalize)
+		compacted := roundTrip(t, cnf, &compact)
+
+		seen := make(map[string]bool)
+		for _, clause := range normalized.Clauses() {
+			if !slices.IsSortedFunc(clause, byVariable) {
+				t.Fatalf("clause %v is not sorted", clause)
+			}
+			for j := 1; j < len(clause); j++ {
+				if clause[j] == clause[j-1] || clause[j] == -clause[j-1] {
+					t.Fatalf("clause %v has duplicate literals or is a tautology", clause)
+				}
+			}
+			key := fmt.Sprint(clause)
+			if seen[key] {
+				t.Fatalf("clause %v is written twice", clause)
+			}
+			seen[key] = true
+		}
+
+		// compaction maps the variables to their rank
+		rank := make(map[sat.Lit]sat.Lit)
+		for _, lits := range [][]sat.Lit{normalized.Lits, normalized.XORs} {
+			for _, lit := range lits {
+				rank[max(lit, -lit)] = 0
+			}
+		}
+		delete(rank, 0)
+		vars := make([]sat.Lit, 0, len(rank))
+		for v := range rank {
+			vars = append(vars, v)
+		}
+		slices.Sort(vars)
+		for i, v := range vars {
+			rank[v] = sat.Lit(i + 1)
+		}
+		renamed := func(lits []sat.Lit) []sat.Lit {
+			out := make([]sat.Lit, 0, len(lits))
+			for _, lit := range lits {
+				if lit < 0 {
+					lit = -rank[-lit]
+				} else if lit > 0 {
+					lit = rank[lit]
+				}
+				out = append(out, lit)
+			}
+			return out
+		}
+		if compacted.NbVars != len(vars) || compacted.NbClauses != normalized.NbClauses ||
+			!slices.Equal(compacted.Lits, renamed(normalized.Lits)) || !slices.Equal(compacted.XORs, renamed(normalized.XORs)) {
+			t.Fatalf("normalized %v and XORs %v compacted to %d variables, %v and XORs %v",
+				normalized.Lits, normalized.XORs, compacted.NbVars, compacted.Lits, compacted.XORs)
+		}
+	}
+}
+
+func TestWriteDIMACSWide(t *testing.T) {
+	cnf := sat.NewWideCNF()
+	cnf.Lits = []sat.WideLit{1 << 40, -3, 0, -(1 << 40), 0}
+	cnf.XORs = []sat.WideLit{3, 1 << 33, 0}
+
+	var buf bytes.Buffer
+	if err := sat.WriteDIMACS(&buf, cnf, nil); err != nil {
+		t.Fatal(err)
+	}
+	read, err := input.ReadWideCNFFile(&buf, input.NewParsingConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.NbVars != 1<<40 || read.NbClauses != 3 || !slices.Equal(read.Lits, cnf.Lits) || !slices.Equal(read.XORs, cnf.XORs) {
+		t.Errorf("read %d variables, %d clauses %v and XORs %v", read.NbVars, read.NbClauses, read.Lits, read.XORs)
+	}
+
+	var compacted bytes.Buffer
+	if err := sat.WriteDIMACS(&compacted, cnf, &sat.WriteConfig{CompactVariables: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := compacted.String(); got != "p cnf 3 3\n3 -1 0\n-3 0\nx1 2 0\n" {
+		t.Errorf("wrote %q", got)
+	}
+}
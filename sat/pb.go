@@ -0,0 +1,60 @@
+package sat
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrPBOverflow is returned by Normalize if a coefficient or the
+// degree of the normalized constraint does not fit into 64 bits
+var ErrPBOverflow = errors.New("normalized constraint exceeds 64 bits")
+
+// Normalize returns constraints equivalent to c with relation
+// GreaterEqual and positive coefficients. A constraint with
+// relation Equal yields two constraints, any other yields one.
+// Negative coefficients are eliminated using a·l = a - a·¬l.
+func (c *PBConstraint) Normalize() ([]PBConstraint, error) {
+	switch c.Relation {
+	case GreaterEqual:
+		n, err := c.normalized(1)
+		return []PBConstraint{n}, err
+	case LessEqual:
+		n, err := c.normalized(-1)
+		return []PBConstraint{n}, err
+	}
+	ge, err := c.normalized(1)
+	if err != nil {
+		return nil, err
+	}
+	le, err := c.normalized(-1)
+	return []PBConstraint{ge, le}, err
+}
+
+// normalized multiplies c by sign and eliminates negative coefficients
+func (c *PBConstraint) normalized(sign int64) (PBConstraint, error) {
+	if sign < 0 && c.Degree == math.MinInt64 {
+		return PBConstraint{}, ErrPBOverflow
+	}
+	n := PBConstraint{
+		Terms:    make([]PBTerm, 0, len(c.Terms)),
+		Relation: GreaterEqual,
+		Degree:   sign * c.Degree,
+	}
+	for _, t := range c.Terms {
+		if t.Coef == math.MinInt64 {
+			return PBConstraint{}, ErrPBOverflow
+		}
+		coef := sign * t.Coef
+		lit := t.Lit
+		if coef < 0 {
+			coef = -coef
+			lit = -lit
+			if n.Degree > math.MaxInt64-coef {
+				return PBConstraint{}, ErrPBOverflow
+			}
+			n.Degree += coef
+		}
+		n.Terms = append(n.Terms, PBTerm{Coef: coef, Lit: lit})
+	}
+	return n, nil
+}
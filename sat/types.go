@@ -1,11 +1,31 @@
 package sat
 
-import "fmt"
+import "math"
 
 // Lit are literals; variables with a sign
 
 type Lit int32
 
+// WideLit are literals of formulas with more variables than Lit can
+// represent; they double the memory footprint of the literals
+
+type WideLit int64
+
+// Literal is satisfied by the literal types Lit and WideLit
+
+type Literal interface {
+	~int32 | ~int64
+}
+
+// VarLimit returns the largest variable representable by literals of type L
+func VarLimit[L Literal]() int {
+	wide := int64(math.MaxInt32) + 1
+	if int64(L(wide)) != wide {
+		return math.MaxInt32
+	}
+	return math.MaxInt64
+}
+
 func (l Lit) Pos() bool {
 	return l > 0
 }
@@ -22,23 +42,212 @@ func (c *Clause) Length() int {
 	return len(*c)
 }
 
-// CNF is a conjunctive normal form; conjunction of clauses
+// Formula is a conjunctive normal form; conjunction of clauses.
+// Its literals are of type L, see CNF and WideCNF.
 
-type CNF struct {
+type Formula[L Literal] struct {
 	NbVars    int
 	NbClauses int
-	Lits      []Lit
+	// MaxVar is the largest variable observed by the parser,
+	// which exceeds NbVars if the header is too small
+	MaxVar int
+	Lits   []L
+	// XORs are XOR constraints (like "x1 -2 3 0" of CryptoMiniSat),
+	// zero-terminated like Lits. An XOR constraint is satisfied iff
+	// an odd number of its literals is true.
+	XORs []L
+	// offsets is the clause index of Lits, see Offsets
+	offsets []int
 }
 
-func NewCNF() *CNF {
-	c := new(CNF)
-	c.Lits = make([]Lit, 0, 65536)
+// CNF is the common formula with 32-bit literals
+type CNF = Formula[Lit]
+
+// WideCNF is a formula with 64-bit literals for more than
+// math.MaxInt32 variables
+type WideCNF = Formula[WideLit]
+
+func NewFormula[L Literal]() *Formula[L] {
+	c := new(Formula[L])
+	c.Lits = make([]L, 0, 65536)
 	return c
 }
 
-func (c *CNF) Dump() {
-	fmt.Println("p cnf " + string(c.NbVars) + " " + string(c.NbClauses))
-	for _, lit := range c.Lits {
-		fmt.Printf("%d ", lit)
+func NewCNF() *CNF {
+	return NewFormula[Lit]()
+}
+
+func NewWideCNF() *WideCNF {
+	return NewFormula[WideLit]()
+}
+
+// VarCount returns the number of variables to size per-variable data
+// structures with: NbVars or MaxVar if the header is too small
+func (c *Formula[L]) VarCount() int {
+	if c.MaxVar > c.NbVars {
+		return c.MaxVar
+	}
+	return c.NbVars
+}
+
+// WCNF is a weighted CNF as used for MaxSAT; every clause of CNF has
+// a weight and clauses with weight Top (or larger) are hard clauses
+
+type WCNF struct {
+	CNF     *CNF
+	Weights []uint64
+	Top     uint64
+}
+
+func NewWCNF() *WCNF {
+	w := new(WCNF)
+	w.CNF = NewCNF()
+	w.Weights = make([]uint64, 0, 1024)
+	return w
+}
+
+// Partition splits the clauses into hard and soft clauses.
+// The weights of the soft clauses are returned in the order of soft.
+func (w *WCNF) Partition() (*CNF, *CNF, []uint64) {
+	hard := NewCNF()
+	soft := NewCNF()
+	hard.NbVars = w.CNF.NbVars
+	soft.NbVars = w.CNF.NbVars
+	hard.MaxVar = w.CNF.MaxVar
+	soft.MaxVar = w.CNF.MaxVar
+	weights := make([]uint64, 0, len(w.Weights))
+
+	for i, clause := range w.CNF.Clauses() {
+		target := hard
+		if i < len(w.Weights) && w.Weights[i] < w.Top {
+			target = soft
+			weights = append(weights, w.Weights[i])
+		}
+		target.Lits = append(target.Lits, clause...)
+		target.Lits = append(target.Lits, 0)
+		target.NbClauses += 1
+	}
+
+	return hard, soft, weights
+}
+
+// Quantifier quantifies the variables of a QBF quantifier block
+
+type Quantifier byte
+
+const (
+	Exists Quantifier = 'e'
+	Forall Quantifier = 'a'
+)
+
+// QuantifierBlock is a sequence of variables with the same quantifier
+
+type QuantifierBlock struct {
+	Quantifier Quantifier
+	Vars       []Lit
+}
+
+// QBF is a quantified boolean formula in prenex CNF; the outermost
+// quantifier block comes first in Prefix
+
+type QBF struct {
+	Prefix []QuantifierBlock
+	Matrix *CNF
+}
+
+func NewQBF() *QBF {
+	q := new(QBF)
+	q.Prefix = make([]QuantifierBlock, 0, 8)
+	q.Matrix = NewCNF()
+	return q
+}
+
+// Increment is a solver call of an incremental CNF; the first
+// NbClauses clauses (NbLits literals including terminating zeros)
+// are solved under Assumptions
+
+type Increment struct {
+	NbVars      int
+	NbClauses   int
+	NbLits      int
+	Assumptions []Lit
+}
+
+// ICNF is an incremental CNF in the iCNF format. CNF contains all
+// clauses, Increments the solver calls in the order of the file.
+
+type ICNF struct {
+	CNF        *CNF
+	Increments []Increment
+}
+
+func NewICNF() *ICNF {
+	f := new(ICNF)
+	f.CNF = NewCNF()
+	f.Increments = make([]Increment, 0, 16)
+	return f
+}
+
+// Prefix returns the CNF of all clauses given until increment i.
+// Its literals are shared with the CNF of f.
+func (f *ICNF) Prefix(i int) *CNF {
+	inc := f.Increments[i]
+	return &CNF{NbVars: inc.NbVars, NbClauses: inc.NbClauses, Lits: f.CNF.Lits[:inc.NbLits]}
+}
+
+// PBTerm is a term of a linear pseudo-Boolean constraint;
+// a literal with an integer coefficient
+
+type PBTerm struct {
+	Coef int64
+	Lit  Lit
+}
+
+// PBRelation relates the sum of terms and the degree of a PBConstraint
+
+type PBRelation byte
+
+const (
+	GreaterEqual PBRelation = 'g'
+	Equal        PBRelation = 'e'
+	LessEqual    PBRelation = 'l'
+)
+
+func (r PBRelation) String() string {
+	switch r {
+	case GreaterEqual:
+		return ">="
+	case Equal:
+		return "="
+	case LessEqual:
+		return "<="
 	}
+	return "?"
+}
+
+// PBConstraint is a linear pseudo-Boolean constraint;
+// the sum of Terms related to Degree by Relation
+
+type PBConstraint struct {
+	Terms    []PBTerm
+	Relation PBRelation
+	Degree   int64
+}
+
+// PBF is a pseudo-Boolean formula as given in OPB files; an optional
+// objective function and a conjunction of constraints
+
+type PBF struct {
+	NbVars        int
+	NbConstraints int
+	Minimize      bool
+	Objective     []PBTerm
+	Constraints   []PBConstraint
+}
+
+func NewPBF() *PBF {
+	p := new(PBF)
+	p.Minimize = true
+	p.Constraints = make([]PBConstraint, 0, 1024)
+	return p
 }
@@ -0,0 +1,103 @@
+package sat
+
+import "math/bits"
+
+// ExpandXOR passes clauses equivalent to the XOR constraint xor to emit.
+// A constraint of length k yields 2^(k-1) clauses, hence constraints
+// longer than cut (at least 3) are split into constraints of at most
+// cut literals connected by fresh variables numbered after nbvars.
+// The slice passed to emit is reused. ExpandXOR returns the number of
+// variables including the fresh ones.
+func ExpandXOR[L Literal](xor []L, cut int, nbvars int, emit func(clause []L) error) (int, error) {
+	for len(xor) > cut {
+		// l1 ⊕ … ⊕ lk ≡ (l1 ⊕ … ⊕ l(cut-1) ⊕ ¬t) ∧ (t ⊕ l(cut) ⊕ … ⊕ lk)
+		nbvars += 1
+		t := L(nbvars)
+		part := make([]L, 0, cut)
+		part = append(part, xor[:cut-1]...)
+		part = append(part, -t)
+		err := expandShortXOR(part, emit)
+		if err != nil {
+			return nbvars, err
+		}
+		rest := make([]L, 0, len(xor)-cut+2)
+		rest = append(rest, t)
+		xor = append(rest, xor[cut-1:]...)
+	}
+	return nbvars, expandShortXOR(xor, emit)
+}
+
+// expandShortXOR emits one clause for each assignment of the literals
+// of xor violating it, i.e. with an even number of true literals
+func expandShortXOR[L Literal](xor []L, emit func(clause []L) error) error {
+	clause := make([]L, len(xor))
+	for m := uint(0); m < 1<<uint(len(xor)); m++ {
+		if bits.OnesCount(m)%2 != 0 {
+			continue
+		}
+		for i, lit := range xor {
+			if m&(1<<uint(i)) != 0 {
+				clause[i] = -lit
+			} else {
+				clause[i] = lit
+			}
+		}
+		err := emit(clause)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxVar returns the largest variable occuring in Lits or XORs
+func (c *Formula[L]) maxVar() int {
+	max := 0
+	for _, lits := range [][]L{c.Lits, c.XORs} {
+		for _, lit := range lits {
+			v := int(lit)
+			if v < 0 {
+				v = -v
+			}
+			if v > max {
+				max = v
+			}
+		}
+	}
+	return max
+}
+
+// ExpandXORs replaces the XOR constraints by equivalent clauses, see
+// ExpandXOR. The clauses are passed to emit or, if emit is nil,
+// appended to Lits. NbVars and NbClauses are updated; the header is
+// assumed to count XOR constraints as clauses.
+func (c *Formula[L]) ExpandXORs(cut int, emit func(clause []L) error) error {
+	nbvars := c.NbVars
+	if max := c.maxVar(); max > nbvars {
+		nbvars = max
+	}
+	if emit == nil {
+		emit = func(clause []L) error {
+			c.Lits = append(c.Lits, clause...)
+			c.Lits = append(c.Lits, 0)
+			return nil
+		}
+	}
+	count := func(clause []L) error {
+		c.NbClauses += 1
+		return emit(clause)
+	}
+
+	var err error
+	for xor := range c.XORConstraints() {
+		nbvars, err = ExpandXOR(xor, cut, nbvars, count)
+		if err != nil {
+			return err
+		}
+		c.NbClauses -= 1
+	}
+
+	c.NbVars = nbvars
+	c.XORs = c.XORs[:0]
+	return nil
+}
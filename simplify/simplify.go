@@ -0,0 +1,414 @@
+package simplify
+
+import (
+	"slices"
+
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+// Report counts what each simplification step removed
+type Report struct {
+	// DuplicateLiterals are repeated literals removed from clauses,
+	// Tautologies are clauses removed containing a literal and its
+	// complement
+	DuplicateLiterals int
+	Tautologies       int
+	// UnitVariables are the variables assigned by unit propagation,
+	// which removed SatisfiedClauses and FalsifiedLiterals. Conflict
+	// tells whether it falsified a clause, hence the formula is
+	// unsatisfiable and simplified to the empty clause.
+	UnitVariables     int
+	SatisfiedClauses  int
+	FalsifiedLiterals int
+	Conflict          bool
+	// PureLiterals are the variables assigned by pure literal
+	// elimination, which removed PureClauses
+	PureLiterals int
+	PureClauses  int
+	// SubsumedClauses are clauses removed which are supersets of
+	// (or equal to) another clause
+	SubsumedClauses int
+}
+
+// simplifier keeps the clauses of a formula during simplification
+type simplifier[L sat.Literal] struct {
+	clauses [][]L
+	removed []bool
+	// value of each variable; 0 if unassigned, 1 if true, -1 if false
+	value []int8
+	// clauses containing each literal (indexed by litIndex) in compressed
+	// sparse row layout: occ[occStart[i]:occStart[i+1]]
+	occStart []int
+	occ      []int
+	// frozen variables occur in XOR constraints
+	frozen []bool
+	report *Report
+}
+
+func abs[L sat.Literal](lit L) L {
+	if lit < 0 {
+		return -lit
+	}
+	return lit
+}
+
+// litIndex maps literals to consecutive non-negative integers
+func litIndex[L sat.Literal](lit L) int {
+	if lit < 0 {
+		return 2*int(-lit) + 1
+	}
+	return 2 * int(lit)
+}
+
+// Simplify returns a simplified copy of cnf and what was removed.
+// The steps are applied in this order:
+//
+//  1. removal of duplicate literals and tautologies
+//  2. unit propagation
+//  3. pure literal elimination
+//  4. forward subsumption
+//
+// Variables keep their numbers and the clauses their order. XOR
+// constraints are copied as they are; their variables are never
+// eliminated as pure literals and unit propagation does not consider
+// them. If unit propagation assigns a variable of an XOR constraint,
+// its unit clause is appended to the remaining clauses, such that the
+// result is equisatisfiable. cnf is not modified.
+func Simplify[L sat.Literal](cnf *sat.Formula[L]) (*sat.Formula[L], *Report) {
+	nbvars := cnf.VarCount()
+	for _, lits := range [][]L{cnf.Lits, cnf.XORs} {
+		for _, lit := range lits {
+			if int(abs(lit)) > nbvars {
+				nbvars = int(abs(lit))
+			}
+		}
+	}
+
+	s := &simplifier[L]{report: new(Report)}
+	s.value = make([]int8, nbvars+1)
+	s.frozen = make([]bool, nbvars+1)
+	for _, lit := range cnf.XORs {
+		s.frozen[abs(lit)] = true
+	}
+
+	s.clean(cnf, nbvars)
+	s.index(nbvars)
+	if !s.propagate() {
+		s.report.Conflict = true
+		return s.result(cnf, nbvars), s.report
+	}
+	s.eliminatePure(nbvars)
+	s.subsume()
+	return s.result(cnf, nbvars), s.report
+}
+
+// clean copies the clauses of cnf without duplicate literals
+// and tautologies
+func (s *simplifier[L]) clean(cnf *sat.Formula[L], nbvars int) {
+	// the capacity suffices, hence the clauses share one array
+	lits := make([]L, 0, len(cnf.Lits))
+	// bit 1 for positive, bit 2 for negative occurences
+	marks := make([]uint8, nbvars+1)
+
+	for _, clause := range cnf.Clauses() {
+		start := len(lits)
+		tautology := false
+		for _, lit := range clause {
+			v, bit := abs(lit), uint8(1)
+			if lit < 0 {
+				bit = 2
+			}
+			if marks[v]&bit != 0 {
+				s.report.DuplicateLiterals += 1
+				continue
+			}
+			marks[v] |= bit
+			tautology = tautology || marks[v] == 3
+			lits = append(lits, lit)
+		}
+		for _, lit := range lits[start:] {
+			marks[abs(lit)] = 0
+		}
+
+		if tautology {
+			s.report.Tautologies += 1
+			lits = lits[:start]
+			continue
+		}
+		s.clauses = append(s.clauses, lits[start:len(lits):len(lits)])
+	}
+	s.removed = make([]bool, len(s.clauses))
+}
+
+// index builds the occurence lists of all literals
+func (s *simplifier[L]) index(nbvars int) {
+	s.occStart = make([]int, 2*nbvars+3)
+	for _, clause := range s.clauses {
+		for _, lit := range clause {
+			s.occStart[litIndex(lit)+1] += 1
+		}
+	}
+	for i := 1; i < len(s.occStart); i++ {
+		s.occStart[i] += s.occStart[i-1]
+	}
+	s.occ = make([]int, s.occStart[len(s.occStart)-1])
+	fill := slices.Clone(s.occStart)
+	for c, clause := range s.clauses {
+		for _, lit := range clause {
+			s.occ[fill[litIndex(lit)]] = c
+			fill[litIndex(lit)] += 1
+		}
+	}
+}
+
+// occurences returns the clauses containing lit
+func (s *simplifier[L]) occurences(lit L) []int {
+	i := litIndex(lit)
+	return s.occ[s.occStart[i]:s.occStart[i+1]]
+}
+
+func (s *simplifier[L]) valueOf(lit L) int8 {
+	if lit < 0 {
+		return -s.value[-lit]
+	}
+	return s.value[lit]
+}
+
+func (s *simplifier[L]) assign(lit L) {
+	if lit < 0 {
+		s.value[-lit] = -1
+	} else {
+		s.value[lit] = 1
+	}
+}
+
+// propagate assigns the literals of unit clauses until a fixpoint is
+// reached and removes satisfied clauses and falsified literals. It
+// returns false if a clause is falsified.
+func (s *simplifier[L]) propagate() bool {
+	queue := make([]L, 0)
+	for _, clause := range s.clauses {
+		switch {
+		case len(clause) == 0:
+			return false
+		case len(clause) == 1 && s.valueOf(clause[0]) < 0:
+			return false
+		case len(clause) == 1 && s.valueOf(clause[0]) == 0:
+			s.assign(clause[0])
+			s.report.UnitVariables += 1
+			queue = append(queue, clause[0])
+		}
+	}
+
+	for len(queue) > 0 {
+		lit := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		// clauses containing the complement lost a literal
+		for _, c := range s.occurences(-lit) {
+			var unassigned L
+			count := 0
+			satisfied := false
+			for _, l := range s.clauses[c] {
+				if v := s.valueOf(l); v > 0 {
+					satisfied = true
+					break
+				} else if v == 0 {
+					unassigned = l
+					count += 1
+				}
+			}
+			if satisfied {
+				continue
+			}
+			if count == 0 {
+				return false
+			}
+			if count == 1 {
+				s.assign(unassigned)
+				s.report.UnitVariables += 1
+				queue = append(queue, unassigned)
+			}
+		}
+	}
+
+	for c, clause := range s.clauses {
+		kept := clause[:0]
+		satisfied := false
+		for _, lit := range clause {
+			if v := s.valueOf(lit); v > 0 {
+				satisfied = true
+				break
+			} else if v == 0 {
+				kept = append(kept, lit)
+			}
+		}
+		if satisfied {
+			s.removed[c] = true
+			s.report.SatisfiedClauses += 1
+			continue
+		}
+		s.report.FalsifiedLiterals += len(clause) - len(kept)
+		s.clauses[c] = kept
+	}
+	return true
+}
+
+// eliminatePure assigns literals whose complement does not occur in
+// the remaining clauses and removes the clauses they satisfy
+func (s *simplifier[L]) eliminatePure(nbvars int) {
+	count := make([]int, 2*nbvars+2)
+	for c, clause := range s.clauses {
+		if s.removed[c] {
+			continue
+		}
+		for _, lit := range clause {
+			count[litIndex(lit)] += 1
+		}
+	}
+
+	// pure returns the pure literal of variable v or 0
+	pure := func(v L) L {
+		if s.frozen[v] || s.value[v] != 0 {
+			return 0
+		}
+		pos, neg := count[litIndex(v)], count[litIndex(-v)]
+		if pos > 0 && neg == 0 {
+			return v
+		} else if neg > 0 && pos == 0 {
+			return -v
+		}
+		return 0
+	}
+
+	queue := make([]L, 0)
+	queued := make([]bool, nbvars+1)
+	push := func(v L) {
+		if lit := pure(v); lit != 0 && !queued[v] {
+			queued[v] = true
+			queue = append(queue, lit)
+		}
+	}
+	for v := 1; v <= nbvars; v++ {
+		push(L(v))
+	}
+	for len(queue) > 0 {
+		lit := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if pure(abs(lit)) != lit {
+			// all its clauses have been removed meanwhile
+			continue
+		}
+		s.assign(lit)
+		s.report.PureLiterals += 1
+
+		for _, c := range s.occurences(lit) {
+			if s.removed[c] {
+				continue
+			}
+			s.removed[c] = true
+			s.report.PureClauses += 1
+			for _, l := range s.clauses[c] {
+				count[litIndex(l)] -= 1
+				if count[litIndex(l)] == 0 {
+					// the complement may have become pure
+					push(abs(l))
+				}
+			}
+		}
+	}
+}
+
+// subsume removes clauses which are supersets of another clause.
+// Clauses are considered in the order of their lengths and every
+// remaining clause is watched by its literal with fewest occurences,
+// such that a clause is only compared with the remaining clauses
+// watched by one of its literals.
+func (s *simplifier[L]) subsume() {
+	order := make([]int, 0, len(s.clauses))
+	for c := range s.clauses {
+		if !s.removed[c] {
+			order = append(order, c)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return len(s.clauses[a]) - len(s.clauses[b])
+	})
+
+	watches := make(map[int][]int)
+	marks := make([]bool, len(s.occStart))
+	for _, d := range order {
+		clause := s.clauses[d]
+		for _, lit := range clause {
+			marks[litIndex(lit)] = true
+		}
+
+		subsumed := false
+		for _, lit := range clause {
+			for _, c := range watches[litIndex(lit)] {
+				subset := true
+				for _, l := range s.clauses[c] {
+					if !marks[litIndex(l)] {
+						subset = false
+						break
+					}
+				}
+				if subset {
+					subsumed = true
+					break
+				}
+			}
+			if subsumed {
+				break
+			}
+		}
+
+		for _, lit := range clause {
+			marks[litIndex(lit)] = false
+		}
+		if subsumed {
+			s.removed[d] = true
+			s.report.SubsumedClauses += 1
+			continue
+		}
+
+		watch := clause[0]
+		for _, lit := range clause[1:] {
+			if len(s.occurences(lit)) < len(s.occurences(watch)) {
+				watch = lit
+			}
+		}
+		watches[litIndex(watch)] = append(watches[litIndex(watch)], d)
+	}
+}
+
+// result returns the formula of the remaining clauses, or the empty
+// clause after a conflict
+func (s *simplifier[L]) result(cnf *sat.Formula[L], nbvars int) *sat.Formula[L] {
+	f := sat.NewFormula[L]()
+	f.NbVars = nbvars
+	f.MaxVar = nbvars
+	f.XORs = slices.Clone(cnf.XORs)
+	if s.report.Conflict {
+		f.Lits = append(f.Lits, 0)
+		f.NbClauses = 1
+		return f
+	}
+	for c, clause := range s.clauses {
+		if s.removed[c] {
+			continue
+		}
+		f.Lits = append(f.Lits, clause...)
+		f.Lits = append(f.Lits, 0)
+		f.NbClauses += 1
+	}
+	// the XOR constraints are not simplified, hence they depend on
+	// the assignments of their variables
+	for v := 1; v <= nbvars; v++ {
+		if s.frozen[v] && s.value[v] != 0 {
+			f.Lits = append(f.Lits, L(v)*L(s.value[v]), 0)
+			f.NbClauses += 1
+		}
+	}
+	return f
+}
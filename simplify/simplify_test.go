@@ -0,0 +1,84 @@
+package simplify
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+// satisfiable tells whether an assignment of the variables up to
+// nbvars satisfies all clauses and XOR constraints of f
+func satisfiable(f *sat.CNF, nbvars int) bool {
+	isTrue := func(m int, lit sat.Lit) bool {
+		if lit < 0 {
+			return m&(1<<uint(-lit-1)) == 0
+		}
+		return m&(1<<uint(lit-1)) != 0
+	}
+	for m := 0; m < 1<<uint(nbvars); m++ {
+		ok := true
+		for _, clause := range f.Clauses() {
+			satisfied := false
+			for _, lit := range clause {
+				satisfied = satisfied || isTrue(m, lit)
+			}
+			ok = ok && satisfied
+		}
+		for xor := range f.XORConstraints() {
+			parity := false
+			for _, lit := range xor {
+				parity = parity != isTrue(m, lit)
+			}
+			ok = ok && parity
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func checkEquisatisfiable(t *testing.T, cnf *sat.CNF, nbvars int) {
+	t.Helper()
+	simplified, _ := Simplify(cnf)
+	if satisfiable(cnf, nbvars) != satisfiable(simplified, nbvars) {
+		t.Fatalf("clauses %v and XORs %v simplified to clauses %v", cnf.Lits, cnf.XORs, simplified.Lits)
+	}
+}
+
+func TestSimplifyUnitOfXORVariable(t *testing.T) {
+	cnf := sat.NewCNF()
+	cnf.Lits = append(cnf.Lits, -1, 0)
+	cnf.XORs = append(cnf.XORs, 1, 0)
+	checkEquisatisfiable(t, cnf, 1)
+}
+
+func TestSimplifyEquisatisfiable(t *testing.T) {
+	const nbvars = 6
+	rng := rand.New(rand.NewSource(1))
+	literal := func() sat.Lit {
+		lit := sat.Lit(rng.Intn(nbvars) + 1)
+		if rng.Intn(2) == 0 {
+			return -lit
+		}
+		return lit
+	}
+
+	for i := 0; i < 2000; i++ {
+		cnf := sat.NewCNF()
+		for c := rng.Intn(10); c > 0; c-- {
+			for k := rng.Intn(3) + 1; k > 0; k-- {
+				cnf.Lits = append(cnf.Lits, literal())
+			}
+			cnf.Lits = append(cnf.Lits, 0)
+		}
+		for x := rng.Intn(3); x > 0; x-- {
+			for k := rng.Intn(3) + 1; k > 0; k-- {
+				cnf.XORs = append(cnf.XORs, literal())
+			}
+			cnf.XORs = append(cnf.XORs, 0)
+		}
+		checkEquisatisfiable(t, cnf, nbvars)
+	}
+}
@@ -0,0 +1,89 @@
+package solver
+
+// varHeap is a binary max-heap of variables ordered by activity
+type varHeap struct {
+	heap []int
+	// index of each variable in heap or -1
+	index    []int
+	activity []float64
+}
+
+func newVarHeap(activity []float64) *varHeap {
+	h := &varHeap{activity: activity}
+	h.index = make([]int, len(activity))
+	for v := range h.index {
+		h.index[v] = -1
+	}
+	return h
+}
+
+func (h *varHeap) empty() bool {
+	return len(h.heap) == 0
+}
+
+func (h *varHeap) contains(v int) bool {
+	return h.index[v] >= 0
+}
+
+func (h *varHeap) insert(v int) {
+	h.index[v] = len(h.heap)
+	h.heap = append(h.heap, v)
+	h.up(h.index[v])
+}
+
+// removeMax removes and returns the variable of highest activity
+func (h *varHeap) removeMax() int {
+	v := h.heap[0]
+	last := h.heap[len(h.heap)-1]
+	h.heap = h.heap[:len(h.heap)-1]
+	h.index[v] = -1
+	if len(h.heap) > 0 {
+		h.heap[0] = last
+		h.index[last] = 0
+		h.down(0)
+	}
+	return v
+}
+
+// increased restores the heap after the activity of v increased
+func (h *varHeap) increased(v int) {
+	if h.contains(v) {
+		h.up(h.index[v])
+	}
+}
+
+func (h *varHeap) up(i int) {
+	v := h.heap[i]
+	for i > 0 {
+		parent := (i - 1) / 2
+		if h.activity[h.heap[parent]] >= h.activity[v] {
+			break
+		}
+		h.heap[i] = h.heap[parent]
+		h.index[h.heap[i]] = i
+		i = parent
+	}
+	h.heap[i] = v
+	h.index[v] = i
+}
+
+func (h *varHeap) down(i int) {
+	v := h.heap[i]
+	for {
+		child := 2*i + 1
+		if child >= len(h.heap) {
+			break
+		}
+		if child+1 < len(h.heap) && h.activity[h.heap[child+1]] > h.activity[h.heap[child]] {
+			child += 1
+		}
+		if h.activity[h.heap[child]] <= h.activity[v] {
+			break
+		}
+		h.heap[i] = h.heap[child]
+		h.index[h.heap[i]] = i
+		i = child
+	}
+	h.heap[i] = v
+	h.index[v] = i
+}
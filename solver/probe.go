@@ -0,0 +1,158 @@
+package solver
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+// DefaultProbeDepths are the depths of DPLL probing used by SATzilla
+var DefaultProbeDepths = []int{1, 4, 16, 64, 256}
+
+type ProbeConfig struct {
+	// Depths are the numbers of random decisions after which the unit
+	// propagations are counted, in increasing order
+	Depths []int
+	// Probes is the number of random probes estimating the search space
+	Probes int
+	// Seed initializes the random decisions
+	Seed int64
+}
+
+func NewProbeConfig() *ProbeConfig {
+	pc := new(ProbeConfig)
+	pc.Depths = DefaultProbeDepths
+	pc.Probes = 10
+	pc.Seed = 1
+	return pc
+}
+
+// ProbeResult describes the random DPLL probes of a formula
+type ProbeResult struct {
+	// UnitPropagations[i] is the number of literals assigned by unit
+	// propagation after Depths[i] random decisions (not counting
+	// assignments implied by the formula without decisions). If a
+	// conflict occurs or all variables are assigned before, the number
+	// reached then is kept for the larger depths.
+	Depths           []int
+	UnitPropagations []int
+	// ProbeDepthMean is the mean number of decisions of the random
+	// probes until a conflict occured or all variables were assigned
+	ProbeDepthMean float64
+	// SearchSpaceLog2 is the binary logarithm of the estimated number
+	// of nodes of a DPLL search tree; a probe of depth d yields an
+	// estimate of 2^(d+1)-1 nodes and the estimates are averaged
+	SearchSpaceLog2 float64
+	// Probes is the number of random probes made, which is 0 if
+	// the formula is unsatisfiable by unit propagation
+	Probes int
+}
+
+func NewProbeResult() *ProbeResult {
+	return new(ProbeResult)
+}
+
+// Probe makes random decisions followed by unit propagation on cnf.
+// The decisions only depend on conf.Seed, hence the result is
+// deterministic. XOR constraints are expanded into clauses as in
+// Solve. If the formula is unsatisfiable by unit propagation or
+// has too many variables, no probes are made and all numbers are 0.
+func Probe[L sat.Literal](cnf *sat.Formula[L], conf *ProbeConfig) *ProbeResult {
+	if conf == nil {
+		conf = NewProbeConfig()
+	}
+	r := NewProbeResult()
+	r.Depths = conf.Depths
+	r.UnitPropagations = make([]int, len(conf.Depths))
+
+	s, _ := load(cnf, NewBudget(), NewResult())
+	if s == nil || !s.ok || s.propagate() != nil {
+		return r
+	}
+
+	// only variables occuring in clauses are decided
+	occurs := make([]bool, s.nbvars+1)
+	vars := make([]int, 0)
+	for _, c := range s.clauses {
+		for _, l := range c.lits {
+			if v := l.variable(); !occurs[v] {
+				occurs[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+
+	rng := rand.New(rand.NewSource(conf.Seed))
+	maxDepth := 0
+	if len(conf.Depths) > 0 {
+		maxDepth = conf.Depths[len(conf.Depths)-1]
+	}
+	s.probe(vars, rng, maxDepth, func(depth, units int) {
+		for i, d := range conf.Depths {
+			if d >= depth {
+				r.UnitPropagations[i] = units
+			}
+		}
+	})
+
+	depths := make([]int, 0, conf.Probes)
+	for i := 0; i < conf.Probes; i++ {
+		depths = append(depths, s.probe(vars, rng, len(vars), nil))
+	}
+	r.Probes = len(depths)
+	if len(depths) > 0 {
+		sum := 0
+		largest := 0
+		for _, d := range depths {
+			sum += d
+			largest = max(largest, d+1)
+		}
+		r.ProbeDepthMean = float64(sum) / float64(len(depths))
+		// log2(mean(2^(d+1)) - 1) without overflow
+		total := 0.0
+		for _, d := range depths {
+			total += math.Exp2(float64(d + 1 - largest))
+		}
+		l := float64(largest) + math.Log2(total/float64(len(depths)))
+		r.SearchSpaceLog2 = l + math.Log2(1-math.Exp2(-l))
+	}
+	return r
+}
+
+// probe decides random unassigned variables of vars with random
+// polarity and propagates them until maxDepth decisions were made, a
+// conflict occured or all variables are assigned. After every decision,
+// report (if non-nil) receives the number of decisions and the number
+// of literals assigned by unit propagation so far. probe returns the
+// number of decisions and undoes them.
+func (s *solver) probe(vars []int, rng *rand.Rand, maxDepth int, report func(depth, units int)) int {
+	base := len(s.trail)
+	order := rng.Perm(len(vars))
+	next := 0
+	depth := 0
+	for depth < maxDepth {
+		for next < len(order) && s.value[2*vars[order[next]]] != 0 {
+			next += 1
+		}
+		if next == len(order) {
+			break
+		}
+		l := lit(2 * vars[order[next]])
+		if rng.Intn(2) == 0 {
+			l = l.neg()
+		}
+		depth += 1
+		s.trailLim = append(s.trailLim, len(s.trail))
+		s.enqueue(l, nil)
+		conflict := s.propagate() != nil
+		if report != nil {
+			report(depth, len(s.trail)-base-depth)
+		}
+		if conflict {
+			break
+		}
+	}
+	s.cancelUntil(0)
+	return depth
+}
@@ -0,0 +1,99 @@
+package solver
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+// equivalences returns the formula of n equivalences x_2i-1 <-> x_2i,
+// hence every decision assigns exactly one literal by propagation
+func equivalences(n int) *sat.CNF {
+	f := sat.NewCNF()
+	f.NbVars = 2 * n
+	for i := 1; i <= n; i++ {
+		a, b := sat.Lit(2*i-1), sat.Lit(2*i)
+		f.Lits = append(f.Lits, -a, b, 0, a, -b, 0)
+		f.NbClauses += 2
+	}
+	return f
+}
+
+func TestProbeEquivalences(t *testing.T) {
+	conf := NewProbeConfig()
+	conf.Depths = []int{1, 2, 5}
+	conf.Probes = 4
+	r := Probe(equivalences(3), conf)
+
+	// all variables are assigned after 3 decisions,
+	// the number reached then is kept for depth 5
+	if !slices.Equal(r.UnitPropagations, []int{1, 2, 3}) {
+		t.Errorf("got unit propagations %v", r.UnitPropagations)
+	}
+	if r.Probes != 4 || r.ProbeDepthMean != 3 {
+		t.Errorf("got %d probes of mean depth %f", r.Probes, r.ProbeDepthMean)
+	}
+	// a probe of depth 3 estimates 2^4-1 nodes
+	if math.Abs(r.SearchSpaceLog2-math.Log2(15)) > 1e-12 {
+		t.Errorf("got search space of 2^%f nodes", r.SearchSpaceLog2)
+	}
+}
+
+func TestProbeDepths(t *testing.T) {
+	conf := NewProbeConfig()
+	conf.Depths = []int{2}
+	conf.Probes = 0
+	r := Probe(equivalences(3), conf)
+
+	// the decisions stop at the largest depth
+	if !slices.Equal(r.Depths, []int{2}) || !slices.Equal(r.UnitPropagations, []int{2}) {
+		t.Errorf("got unit propagations %v at depths %v", r.UnitPropagations, r.Depths)
+	}
+	if r.Probes != 0 || r.ProbeDepthMean != 0 || r.SearchSpaceLog2 != 0 {
+		t.Errorf("got %d probes of mean depth %f and search space of 2^%f nodes",
+			r.Probes, r.ProbeDepthMean, r.SearchSpaceLog2)
+	}
+}
+
+func TestProbeConflicts(t *testing.T) {
+	// every decision leads to a conflict
+	f := &sat.CNF{NbVars: 3, NbClauses: 4, Lits: []sat.Lit{1, 2, 0, 1, -2, 0, -1, 3, 0, -1, -3, 0}}
+	r := Probe(f, nil)
+	if r.Probes != 10 || r.ProbeDepthMean != 1 {
+		t.Errorf("got %d probes of mean depth %f", r.Probes, r.ProbeDepthMean)
+	}
+	for i := range r.UnitPropagations {
+		if r.UnitPropagations[i] != r.UnitPropagations[0] {
+			t.Errorf("got unit propagations %v, expected the number at depth 1 at all depths", r.UnitPropagations)
+		}
+	}
+
+	// unsatisfiable by unit propagation
+	f = &sat.CNF{NbVars: 2, NbClauses: 3, Lits: []sat.Lit{1, 0, -1, 2, 0, -2, 0}}
+	r = Probe(f, nil)
+	if r.Probes != 0 || slices.Max(r.UnitPropagations) != 0 || r.SearchSpaceLog2 != 0 {
+		t.Errorf("got %d probes and unit propagations %v", r.Probes, r.UnitPropagations)
+	}
+}
+
+func TestProbeDeterministic(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 100; i++ {
+		f := randomFormula(rng, 8)
+		lits := slices.Clone(f.Lits)
+		conf := NewProbeConfig()
+		conf.Seed = int64(i)
+
+		r := Probe(f, conf)
+		if !reflect.DeepEqual(r, Probe(f, conf)) {
+			t.Fatalf("clauses %v and XORs %v: probes with seed %d differ", f.Lits, f.XORs, conf.Seed)
+		}
+		if !slices.Equal(lits, f.Lits) {
+			t.Fatalf("clauses %v modified", lits)
+		}
+	}
+}
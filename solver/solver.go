@@ -0,0 +1,660 @@
+package solver
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+// Status is the answer of the solver
+type Status int8
+
+const (
+	Unknown Status = iota
+	Satisfiable
+	Unsatisfiable
+)
+
+func (s Status) String() string {
+	switch s {
+	case Satisfiable:
+		return "SAT"
+	case Unsatisfiable:
+		return "UNSAT"
+	}
+	return "UNKNOWN"
+}
+
+// Budget limits the search; the solver gives up with Unknown once
+// Conflicts conflicts occured or Time has passed. Zero values mean no
+// limit.
+type Budget struct {
+	Conflicts int
+	Time      time.Duration
+}
+
+func NewBudget() *Budget {
+	return new(Budget)
+}
+
+// Result is the answer of the solver and the effort to find it
+type Result struct {
+	Status Status
+	// Model assigns a value to every variable (index 0 is unused)
+	// if the formula is satisfiable
+	Model []bool
+
+	Conflicts    int
+	Decisions    int
+	Propagations int
+	Restarts     int
+	// LearntClauses were derived from conflicts; LearntLiterals and
+	// LearntLBD are the sums of their lengths and their literal block
+	// distances (number of decision levels) when they were learnt.
+	// DeletedClauses were removed again to bound the database.
+	LearntClauses       int
+	LearntUnits         int
+	LearntLiterals      int
+	LearntLengthLargest int
+	LearntLBD           int
+	DeletedClauses      int
+	Time                time.Duration
+}
+
+func NewResult() *Result {
+	return new(Result)
+}
+
+// xorCutLength is the length XOR constraints are split into
+// before they are expanded into clauses
+const xorCutLength = 5
+
+// lit encodes a literal of variable v as 2v if positive
+// and 2v+1 if negative
+type lit uint32
+
+func (l lit) neg() lit {
+	return l ^ 1
+}
+
+func (l lit) variable() int {
+	return int(l >> 1)
+}
+
+func toLit[L sat.Literal](l L) lit {
+	if l < 0 {
+		return lit(2*uint64(-l) + 1)
+	}
+	return lit(2 * uint64(l))
+}
+
+type clause struct {
+	lits     []lit
+	learnt   bool
+	deleted  bool
+	activity float64
+}
+
+// watcher refers to a clause watching a literal. If blocker is true,
+// the clause is satisfied and need not be visited.
+type watcher struct {
+	c       *clause
+	blocker lit
+}
+
+// solver is a conflict-driven clause learning solver in the style of
+// MiniSat: two watched literals, VSIDS decisions with phase saving,
+// first-UIP learning with clause minimization, Luby restarts and
+// removal of inactive learnt clauses
+type solver struct {
+	nbvars  int
+	ok      bool
+	clauses []*clause
+	learnts []*clause
+	// clauses watching each literal, visited when it becomes false
+	watches [][]watcher
+
+	// value of each literal; 1 if true, -1 if false, 0 if unassigned
+	value    []int8
+	level    []int
+	reason   []*clause
+	trail    []lit
+	trailLim []int
+	qhead    int
+
+	activity []float64
+	varInc   float64
+	order    *varHeap
+	// polarity is true if the variable was last assigned false
+	polarity []bool
+	claInc   float64
+
+	maxLearnts float64
+	seen       []bool
+	// literals marked seen by analyze
+	analyzed   []lit
+	levelStamp []int
+	stamp      int
+
+	budget   *Budget
+	deadline time.Time
+	result   *Result
+}
+
+const (
+	varDecay      = 0.95
+	claDecay      = 0.999
+	restartBase   = 100
+	learntsGrowth = 1.1
+)
+
+// Solve decides the satisfiability of cnf within budget (nil means no
+// limit). XOR constraints are expanded into clauses on a copy; fresh
+// variables are not part of the model. cnf is not modified.
+func Solve[L sat.Literal](cnf *sat.Formula[L], budget *Budget) *Result {
+	start := time.Now()
+	if budget == nil {
+		budget = NewBudget()
+	}
+
+	result := NewResult()
+	s, nbvars := load(cnf, budget, result)
+	if s == nil {
+		result.Time = time.Since(start)
+		return result
+	}
+	if budget.Time > 0 {
+		s.deadline = start.Add(budget.Time)
+	}
+
+	result.Status = s.solve()
+	if result.Status == Satisfiable {
+		result.Model = make([]bool, nbvars+1)
+		for v := 1; v <= nbvars; v++ {
+			result.Model[v] = s.value[2*v] > 0
+		}
+	}
+	result.Time = time.Since(start)
+	return result
+}
+
+// load returns a solver with the clauses and the expanded XOR
+// constraints of cnf and the number of variables of cnf. It returns
+// a nil solver if there are too many variables to encode literals.
+// The clauses are read using the clause index of cnf (see Offsets).
+func load[L sat.Literal](cnf *sat.Formula[L], budget *Budget, result *Result) (*solver, int) {
+	nbvars := cnf.VarCount()
+	for _, lits := range [][]L{cnf.Lits, cnf.XORs} {
+		for _, l := range lits {
+			if l < 0 && int(-l) > nbvars {
+				nbvars = int(-l)
+			} else if int(l) > nbvars {
+				nbvars = int(l)
+			}
+		}
+	}
+
+	// fresh variables of expanded XOR constraints follow nbvars
+	xorClauses := make([]L, 0)
+	nbfresh := nbvars
+	for xor := range cnf.XORConstraints() {
+		nbfresh, _ = sat.ExpandXOR(xor, xorCutLength, nbfresh, func(clause []L) error {
+			xorClauses = append(xorClauses, clause...)
+			xorClauses = append(xorClauses, 0)
+			return nil
+		})
+	}
+	if 2*uint64(nbfresh)+1 > math.MaxUint32 {
+		return nil, nbvars
+	}
+
+	s := newSolver(nbfresh, budget, result)
+	buf := make([]lit, 0)
+	add := func(clause []L) bool {
+		buf = buf[:0]
+		for _, l := range clause {
+			buf = append(buf, toLit(l))
+		}
+		return s.addClause(buf)
+	}
+	for i := 0; i < cnf.NumClauses(); i++ {
+		if !add(cnf.Clause(i)) {
+			return s, nbvars
+		}
+	}
+	for _, clause := range sat.SplitClauses(xorClauses) {
+		if !add(clause) {
+			break
+		}
+	}
+	return s, nbvars
+}
+
+func newSolver(nbvars int, budget *Budget, result *Result) *solver {
+	s := &solver{nbvars: nbvars, ok: true, budget: budget, result: result}
+	s.watches = make([][]watcher, 2*nbvars+2)
+	s.value = make([]int8, 2*nbvars+2)
+	s.level = make([]int, nbvars+1)
+	s.reason = make([]*clause, nbvars+1)
+	s.activity = make([]float64, nbvars+1)
+	s.polarity = make([]bool, nbvars+1)
+	s.seen = make([]bool, nbvars+1)
+	s.levelStamp = make([]int, nbvars+1)
+	s.varInc = 1
+	s.claInc = 1
+	s.order = newVarHeap(s.activity)
+	for v := 1; v <= nbvars; v++ {
+		s.polarity[v] = true
+		s.order.insert(v)
+	}
+	return s
+}
+
+func (s *solver) decisionLevel() int {
+	return len(s.trailLim)
+}
+
+// addClause adds a clause of the formula before the search.
+// It returns false if the formula is found to be unsatisfiable.
+func (s *solver) addClause(lits []lit) bool {
+	if !s.ok {
+		return false
+	}
+	// complementary literals are adjacent after sorting
+	slices.Sort(lits)
+	kept := make([]lit, 0, len(lits))
+	for i, l := range lits {
+		if i > 0 && l == lits[i-1] || s.value[l] < 0 {
+			continue
+		}
+		if s.value[l] > 0 || i > 0 && l == lits[i-1].neg() {
+			return true
+		}
+		kept = append(kept, l)
+	}
+
+	switch len(kept) {
+	case 0:
+		s.ok = false
+	case 1:
+		s.enqueue(kept[0], nil)
+		s.ok = s.propagate() == nil
+	default:
+		c := &clause{lits: kept}
+		s.clauses = append(s.clauses, c)
+		s.attach(c)
+	}
+	return s.ok
+}
+
+func (s *solver) attach(c *clause) {
+	s.watches[c.lits[0]] = append(s.watches[c.lits[0]], watcher{c, c.lits[1]})
+	s.watches[c.lits[1]] = append(s.watches[c.lits[1]], watcher{c, c.lits[0]})
+}
+
+// enqueue assigns l true, implied by from (nil for decisions)
+func (s *solver) enqueue(l lit, from *clause) {
+	s.value[l] = 1
+	s.value[l.neg()] = -1
+	v := l.variable()
+	s.level[v] = s.decisionLevel()
+	s.reason[v] = from
+	s.trail = append(s.trail, l)
+}
+
+// propagate assigns the literals implied by the assignments of the trail
+// and returns a falsified clause or nil. The implied literal of a reason
+// clause is its first literal.
+func (s *solver) propagate() *clause {
+	for s.qhead < len(s.trail) {
+		falseLit := s.trail[s.qhead].neg()
+		s.qhead += 1
+		s.result.Propagations += 1
+
+		ws := s.watches[falseLit]
+		i, j := 0, 0
+	next:
+		for i < len(ws) {
+			w := ws[i]
+			i += 1
+			if w.c.deleted {
+				continue
+			}
+			blocker := w.blocker
+			if s.value[blocker] > 0 {
+				ws[j] = w
+				j += 1
+				continue
+			}
+
+			c := w.c
+			if c.lits[0] == falseLit {
+				c.lits[0], c.lits[1] = c.lits[1], c.lits[0]
+			}
+			first := c.lits[0]
+			w = watcher{c, first}
+			if first != blocker && s.value[first] > 0 {
+				ws[j] = w
+				j += 1
+				continue
+			}
+
+			for k := 2; k < len(c.lits); k++ {
+				if s.value[c.lits[k]] >= 0 {
+					c.lits[1], c.lits[k] = c.lits[k], c.lits[1]
+					s.watches[c.lits[1]] = append(s.watches[c.lits[1]], w)
+					continue next
+				}
+			}
+
+			ws[j] = w
+			j += 1
+			if s.value[first] < 0 {
+				j += copy(ws[j:], ws[i:])
+				s.watches[falseLit] = ws[:j]
+				s.qhead = len(s.trail)
+				return c
+			}
+			s.enqueue(first, c)
+		}
+		s.watches[falseLit] = ws[:j]
+	}
+	return nil
+}
+
+// analyze derives the first-UIP clause of the conflicting clause confl.
+// The asserting literal is the first literal of the learnt clause, a
+// literal of the level to backtrack to is the second one.
+func (s *solver) analyze(confl *clause) ([]lit, int) {
+	learnt := []lit{0}
+	pathCount := 0
+	var p lit
+	index := len(s.trail) - 1
+
+	for {
+		if confl.learnt {
+			s.bumpClause(confl)
+		}
+		lits := confl.lits
+		if pathCount > 0 {
+			// the first literal is p itself
+			lits = lits[1:]
+		}
+		for _, q := range lits {
+			v := q.variable()
+			if s.seen[v] || s.level[v] == 0 {
+				continue
+			}
+			s.bumpVar(v)
+			s.seen[v] = true
+			if s.level[v] >= s.decisionLevel() {
+				pathCount += 1
+			} else {
+				learnt = append(learnt, q)
+			}
+		}
+
+		for !s.seen[s.trail[index].variable()] {
+			index -= 1
+		}
+		p = s.trail[index]
+		index -= 1
+		confl = s.reason[p.variable()]
+		s.seen[p.variable()] = false
+		pathCount -= 1
+		if pathCount == 0 {
+			break
+		}
+	}
+	learnt[0] = p.neg()
+
+	// drop literals implied by other literals of the clause
+	s.analyzed = append(s.analyzed[:0], learnt...)
+	j := 1
+	for _, q := range learnt[1:] {
+		if !s.redundant(q) {
+			learnt[j] = q
+			j += 1
+		}
+	}
+	learnt = learnt[:j]
+	for _, q := range s.analyzed {
+		s.seen[q.variable()] = false
+	}
+
+	backtrack := 0
+	if len(learnt) > 1 {
+		largest := 1
+		for i := 2; i < len(learnt); i++ {
+			if s.level[learnt[i].variable()] > s.level[learnt[largest].variable()] {
+				largest = i
+			}
+		}
+		learnt[1], learnt[largest] = learnt[largest], learnt[1]
+		backtrack = s.level[learnt[1].variable()]
+	}
+	return learnt, backtrack
+}
+
+// redundant tells whether the literals of the reason of q are part
+// of the clause being learnt or assigned at level 0
+func (s *solver) redundant(q lit) bool {
+	r := s.reason[q.variable()]
+	if r == nil {
+		return false
+	}
+	for _, l := range r.lits[1:] {
+		if v := l.variable(); !s.seen[v] && s.level[v] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// lbd returns the number of decision levels of lits
+func (s *solver) lbd(lits []lit) int {
+	s.stamp += 1
+	count := 0
+	for _, l := range lits {
+		if lv := s.level[l.variable()]; s.levelStamp[lv] != s.stamp {
+			s.levelStamp[lv] = s.stamp
+			count += 1
+		}
+	}
+	return count
+}
+
+// learn adds the learnt clause after backtracking
+// and assigns its asserting literal
+func (s *solver) learn(lits []lit) {
+	r := s.result
+	r.LearntClauses += 1
+	r.LearntLiterals += len(lits)
+	r.LearntLBD += s.lbd(lits)
+	if len(lits) > r.LearntLengthLargest {
+		r.LearntLengthLargest = len(lits)
+	}
+
+	if len(lits) == 1 {
+		r.LearntUnits += 1
+		s.enqueue(lits[0], nil)
+		return
+	}
+	c := &clause{lits: lits, learnt: true}
+	s.learnts = append(s.learnts, c)
+	s.attach(c)
+	s.bumpClause(c)
+	s.enqueue(lits[0], c)
+}
+
+// cancelUntil undoes all assignments above level
+func (s *solver) cancelUntil(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.trailLim[level]; i-- {
+		l := s.trail[i]
+		v := l.variable()
+		s.value[l] = 0
+		s.value[l.neg()] = 0
+		s.reason[v] = nil
+		s.polarity[v] = l&1 == 1
+		if !s.order.contains(v) {
+			s.order.insert(v)
+		}
+	}
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+	s.qhead = len(s.trail)
+}
+
+func (s *solver) bumpVar(v int) {
+	s.activity[v] += s.varInc
+	if s.activity[v] > 1e100 {
+		for i := range s.activity {
+			s.activity[i] *= 1e-100
+		}
+		s.varInc *= 1e-100
+	}
+	s.order.increased(v)
+}
+
+func (s *solver) bumpClause(c *clause) {
+	c.activity += s.claInc
+	if c.activity > 1e20 {
+		for _, l := range s.learnts {
+			l.activity *= 1e-20
+		}
+		s.claInc *= 1e-20
+	}
+}
+
+// decide assigns the unassigned variable of highest activity its
+// saved phase and returns false if all variables are assigned
+func (s *solver) decide() bool {
+	for !s.order.empty() {
+		v := s.order.removeMax()
+		if s.value[2*v] != 0 {
+			continue
+		}
+		l := lit(2 * v)
+		if s.polarity[v] {
+			l = l.neg()
+		}
+		s.result.Decisions += 1
+		s.trailLim = append(s.trailLim, len(s.trail))
+		s.enqueue(l, nil)
+		return true
+	}
+	return false
+}
+
+// locked tells whether c is the reason of an assignment
+func (s *solver) locked(c *clause) bool {
+	return s.reason[c.lits[0].variable()] == c && s.value[c.lits[0]] > 0
+}
+
+// reduce removes half of the learnt clauses with lowest activity,
+// except for binary clauses and reasons
+func (s *solver) reduce() {
+	slices.SortFunc(s.learnts, func(a, b *clause) int {
+		if len(a.lits) == 2 || len(b.lits) == 2 {
+			return min(len(b.lits), 3) - min(len(a.lits), 3)
+		}
+		if a.activity < b.activity {
+			return -1
+		} else if a.activity > b.activity {
+			return 1
+		}
+		return 0
+	})
+	half := len(s.learnts) / 2
+	kept := s.learnts[:0]
+	for i, c := range s.learnts {
+		if i < half && len(c.lits) > 2 && !s.locked(c) {
+			c.deleted = true
+			s.result.DeletedClauses += 1
+			continue
+		}
+		kept = append(kept, c)
+	}
+	clear(s.learnts[len(kept):])
+	s.learnts = kept
+}
+
+// exhausted tells whether the budget is used up
+func (s *solver) exhausted() bool {
+	if s.budget.Conflicts > 0 && s.result.Conflicts >= s.budget.Conflicts {
+		return true
+	}
+	return !s.deadline.IsZero() && time.Now().After(s.deadline)
+}
+
+// search runs until the formula is decided, maxConflicts conflicts
+// occured (then it restarts) or the budget is exhausted
+func (s *solver) search(maxConflicts int) Status {
+	conflicts := 0
+	for {
+		confl := s.propagate()
+		if confl != nil {
+			s.result.Conflicts += 1
+			conflicts += 1
+			if s.decisionLevel() == 0 {
+				return Unsatisfiable
+			}
+			learnt, backtrack := s.analyze(confl)
+			s.cancelUntil(backtrack)
+			s.learn(learnt)
+			s.varInc /= varDecay
+			s.claInc /= claDecay
+			continue
+		}
+
+		if conflicts >= maxConflicts || s.exhausted() {
+			s.cancelUntil(0)
+			return Unknown
+		}
+		if float64(len(s.learnts)-len(s.trail)) >= s.maxLearnts {
+			s.reduce()
+			s.maxLearnts *= learntsGrowth
+		}
+		if !s.decide() {
+			return Satisfiable
+		}
+	}
+}
+
+func (s *solver) solve() Status {
+	if !s.ok || s.propagate() != nil {
+		return Unsatisfiable
+	}
+	s.maxLearnts = max(float64(len(s.clauses))/3, 1000)
+	for restart := 0; ; restart++ {
+		status := s.search(int(luby(2, restart) * restartBase))
+		if status != Unknown {
+			return status
+		}
+		if s.exhausted() {
+			return Unknown
+		}
+		s.result.Restarts += 1
+	}
+}
+
+// luby returns the x-th element of the Luby sequence
+// 1, 1, 2, 1, 1, 2, 4, … with y in place of 2
+func luby(y float64, x int) float64 {
+	size, seq := 1, 0
+	for size < x+1 {
+		seq += 1
+		size = 2*size + 1
+	}
+	for size-1 != x {
+		size = (size - 1) >> 1
+		seq -= 1
+		x = x % size
+	}
+	return math.Pow(y, float64(seq))
+}
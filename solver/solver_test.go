@@ -0,0 +1,219 @@
+package solver
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+// satisfies tells whether model satisfies all clauses and XOR
+// constraints of f
+func satisfies(f *sat.CNF, model []bool) bool {
+	isTrue := func(l sat.Lit) bool {
+		if l < 0 {
+			return !model[-l]
+		}
+		return model[l]
+	}
+	for _, clause := range f.Clauses() {
+		satisfied := false
+		for _, l := range clause {
+			satisfied = satisfied || isTrue(l)
+		}
+		if !satisfied {
+			return false
+		}
+	}
+	for xor := range f.XORConstraints() {
+		parity := false
+		for _, l := range xor {
+			parity = parity != isTrue(l)
+		}
+		if !parity {
+			return false
+		}
+	}
+	return true
+}
+
+// bruteForce tells whether an assignment of the variables up to nbvars
+// satisfies f
+func bruteForce(f *sat.CNF, nbvars int) bool {
+	model := make([]bool, nbvars+1)
+	for m := 0; m < 1<<uint(nbvars); m++ {
+		for v := 1; v <= nbvars; v++ {
+			model[v] = m&(1<<uint(v-1)) != 0
+		}
+		if satisfies(f, model) {
+			return true
+		}
+	}
+	return false
+}
+
+// randomFormula returns clauses and XOR constraints over nbvars
+// variables. Some XOR constraints are long enough to be cut when
+// they are expanded.
+func randomFormula(rng *rand.Rand, nbvars int) *sat.CNF {
+	literal := func() sat.Lit {
+		l := sat.Lit(rng.Intn(nbvars) + 1)
+		if rng.Intn(2) == 0 {
+			return -l
+		}
+		return l
+	}
+
+	f := sat.NewCNF()
+	f.NbVars = nbvars
+	for c := rng.Intn(4 * nbvars); c > 0; c-- {
+		// rarely empty clauses
+		for k := rng.Intn(4) + rng.Intn(40)/39; k > 0; k-- {
+			f.Lits = append(f.Lits, literal())
+		}
+		f.Lits = append(f.Lits, 0)
+		f.NbClauses += 1
+	}
+	for x := rng.Intn(3); x > 0; x-- {
+		for k := rng.Intn(2*xorCutLength) + 1; k > 0; k-- {
+			f.XORs = append(f.XORs, literal())
+		}
+		f.XORs = append(f.XORs, 0)
+	}
+	return f
+}
+
+func TestSolveRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		nbvars := rng.Intn(8) + 1
+		f := randomFormula(rng, nbvars)
+
+		result := Solve(f, nil)
+		expected := Unsatisfiable
+		if bruteForce(f, nbvars) {
+			expected = Satisfiable
+		}
+		if result.Status != expected {
+			t.Fatalf("clauses %v and XORs %v: got %v, expected %v", f.Lits, f.XORs, result.Status, expected)
+		}
+		if result.Status == Satisfiable && !satisfies(f, result.Model) {
+			t.Fatalf("clauses %v and XORs %v: model %v does not satisfy the formula", f.Lits, f.XORs, result.Model)
+		}
+	}
+}
+
+// pigeonhole returns the formula placing pigeons into holes such that
+// no hole holds two pigeons
+func pigeonhole(pigeons, holes int) *sat.CNF {
+	f := sat.NewCNF()
+	f.NbVars = pigeons * holes
+	in := func(p, h int) sat.Lit {
+		return sat.Lit(p*holes + h + 1)
+	}
+	for p := 0; p < pigeons; p++ {
+		for h := 0; h < holes; h++ {
+			f.Lits = append(f.Lits, in(p, h))
+		}
+		f.Lits = append(f.Lits, 0)
+		f.NbClauses += 1
+	}
+	for h := 0; h < holes; h++ {
+		for p := 0; p < pigeons; p++ {
+			for q := p + 1; q < pigeons; q++ {
+				f.Lits = append(f.Lits, -in(p, h), -in(q, h), 0)
+				f.NbClauses += 1
+			}
+		}
+	}
+	return f
+}
+
+func TestSolvePigeonhole(t *testing.T) {
+	for holes := 1; holes <= 7; holes++ {
+		f := pigeonhole(holes+1, holes)
+		result := Solve(f, nil)
+		if result.Status != Unsatisfiable {
+			t.Errorf("%d pigeons, %d holes: got %v", holes+1, holes, result.Status)
+		}
+		// restarts and the reduction of learnt clauses are reached
+		if holes == 7 && (result.Restarts == 0 || result.DeletedClauses == 0) {
+			t.Errorf("%d pigeons, %d holes: %d restarts, %d deleted clauses",
+				holes+1, holes, result.Restarts, result.DeletedClauses)
+		}
+
+		f = pigeonhole(holes, holes)
+		result = Solve(f, nil)
+		if result.Status != Satisfiable || !satisfies(f, result.Model) {
+			t.Errorf("%d pigeons, %d holes: got %v with model %v", holes, holes, result.Status, result.Model)
+		}
+	}
+}
+
+func TestSolveUnsatisfiable(t *testing.T) {
+	formulas := map[string]*sat.CNF{
+		"empty clause":      {NbVars: 1, NbClauses: 2, Lits: []sat.Lit{1, 0, 0}},
+		"complementary":     {NbVars: 1, NbClauses: 2, Lits: []sat.Lit{1, 0, -1, 0}},
+		"all assignments":   {NbVars: 2, NbClauses: 4, Lits: []sat.Lit{1, 2, 0, 1, -2, 0, -1, 2, 0, -1, -2, 0}},
+		"XOR contradiction": {NbVars: 2, XORs: []sat.Lit{1, 2, 0, -1, 2, 0}},
+		"XOR and clauses":   {NbVars: 2, NbClauses: 2, Lits: []sat.Lit{1, 0, 2, 0}, XORs: []sat.Lit{1, 2, 0}},
+	}
+	for name, f := range formulas {
+		result := Solve(f, nil)
+		if result.Status != Unsatisfiable {
+			t.Errorf("%s: got %v", name, result.Status)
+		}
+	}
+}
+
+func TestSolveBudget(t *testing.T) {
+	budget := NewBudget()
+	budget.Conflicts = 10
+	result := Solve(pigeonhole(9, 8), budget)
+	if result.Status != Unknown {
+		t.Errorf("got %v after %d conflicts", result.Status, result.Conflicts)
+	}
+}
+
+func TestSolveWide(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 200; i++ {
+		f := randomFormula(rng, 6)
+		wide := sat.NewWideCNF()
+		wide.NbVars = f.NbVars
+		wide.NbClauses = f.NbClauses
+		for _, l := range f.Lits {
+			wide.Lits = append(wide.Lits, sat.WideLit(l))
+		}
+		for _, l := range f.XORs {
+			wide.XORs = append(wide.XORs, sat.WideLit(l))
+		}
+		if Solve(f, nil).Status != Solve(wide, nil).Status {
+			t.Fatalf("clauses %v and XORs %v: results differ", f.Lits, f.XORs)
+		}
+	}
+}
+
+func TestVarHeap(t *testing.T) {
+	activity := []float64{0, 3, 1, 4, 1, 5, 9, 2, 6}
+	h := newVarHeap(activity)
+	for v := 1; v < len(activity); v++ {
+		h.insert(v)
+	}
+	activity[4] = 7
+	h.increased(4)
+
+	order := make([]int, 0)
+	for !h.empty() {
+		order = append(order, h.removeMax())
+	}
+	expected := []int{6, 4, 8, 5, 3, 1, 7, 2}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("got order %v, expected %v", order, expected)
+		}
+	}
+	if h.contains(6) {
+		t.Fatalf("removed variable still contained")
+	}
+}
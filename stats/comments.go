@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"strings"
+
+	"github.com/prokls/cnf-analysis-go/input"
+	"github.com/prokls/cnf-analysis-go/output"
+)
+
+// isKey tells whether s can be the key of a key/value pair in a comment
+func isKey(s string) bool {
+	return s != "" && !strings.ContainsAny(s, " \t\v\f\r:=")
+}
+
+// commentPairs extracts key/value pairs of a comment like
+// "generator: foo 1.2" or "seed=42 family=bmc". A line with a colon
+// is one pair; otherwise every field must be a "key=value" pair.
+func commentPairs(comment string) [][2]string {
+	colon := strings.IndexByte(comment, ':')
+	equals := strings.IndexByte(comment, '=')
+	if colon > 0 && (equals < 0 || colon < equals) {
+		key := strings.TrimSpace(comment[:colon])
+		if isKey(key) {
+			return [][2]string{{key, strings.TrimSpace(comment[colon+1:])}}
+		}
+		return nil
+	}
+
+	var pairs [][2]string
+	fields := strings.Fields(comment)
+	for _, field := range fields {
+		i := strings.IndexByte(field, '=')
+		if i <= 0 {
+			// "key = value"
+			if len(fields) == 3 && fields[1] == "=" && isKey(fields[0]) {
+				return [][2]string{{fields[0], fields[2]}}
+			}
+			return nil
+		}
+		if !isKey(field[:i]) {
+			return nil
+		}
+		pairs = append(pairs, [2]string{field[:i], field[i+1:]})
+	}
+	return pairs
+}
+
+// Comments stores the comment lines collected in report in s
+func Comments(s *output.Stats, report *input.Report) {
+	s.Comments = output.NewComments()
+	s.Comments.LinesCount = uint64(len(report.Comments))
+	s.Comments.BytesCount = uint64(report.CommentBytes)
+	for _, comment := range report.Comments {
+		for _, pair := range commentPairs(comment) {
+			if _, ok := s.Comments.Values[pair[0]]; !ok {
+				s.Comments.Values[pair[0]] = pair[1]
+			}
+		}
+	}
+}
@@ -3,8 +3,46 @@ package stats
 type FeatureConfig struct {
 	Hashes   bool
 	FullPath bool
+	// Digests names the digests computed if Hashes is set; cnfhash
+	// is computed of the (decompressed) content only
+	Digests []string
+	// NoCNFHash skips cnfhash which is only defined for DIMACS CNF files
+	NoCNFHash bool
+	// Member is the path of the file inside the archive given by path
+	Member string
 }
 
 func NewFeatureConfig() *FeatureConfig {
-	return new(FeatureConfig)
+	fc := new(FeatureConfig)
+	fc.Hashes = true
+	fc.Digests = DefaultDigests
+	return fc
+}
+
+// ContentDigests returns the digests computed of the content
+func (conf *FeatureConfig) ContentDigests() []string {
+	if !conf.Hashes {
+		return nil
+	}
+	names := make([]string, 0, len(conf.Digests))
+	for _, name := range conf.Digests {
+		if name != CNFHashDigest || !conf.NoCNFHash {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// CompressedDigests returns the digests computed of compressed input
+func (conf *FeatureConfig) CompressedDigests() []string {
+	if !conf.Hashes {
+		return nil
+	}
+	names := make([]string, 0, len(conf.Digests))
+	for _, name := range conf.Digests {
+		if name != CNFHashDigest {
+			names = append(names, name)
+		}
+	}
+	return names
 }
@@ -0,0 +1,157 @@
+package stats
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/prokls/cnf-hash-go/cnfhash"
+	"golang.org/x/crypto/blake2b"
+)
+
+// names of the digests supported by Digester
+const (
+	MD5Digest     = "md5"
+	SHA1Digest    = "sha1"
+	SHA256Digest  = "sha256"
+	BLAKE2bDigest = "blake2b"
+	CNFHashDigest = "cnfhash"
+)
+
+// Digests lists the names of all supported digests
+var Digests = []string{MD5Digest, SHA1Digest, SHA256Digest, BLAKE2bDigest, CNFHashDigest}
+
+// DefaultDigests are computed unless other digests are selected
+var DefaultDigests = []string{MD5Digest, SHA1Digest, CNFHashDigest}
+
+// number of chunks buffered per digest before Write blocks
+const digestBacklog = 16
+
+func newHash(name string) hash.Hash {
+	switch name {
+	case MD5Digest:
+		return md5.New()
+	case SHA1Digest:
+		return sha1.New()
+	case SHA256Digest:
+		return sha256.New()
+	case BLAKE2bDigest:
+		h, _ := blake2b.New512(nil)
+		return h
+	}
+	return nil
+}
+
+// ValidDigest tells whether name is a supported digest
+func ValidDigest(name string) bool {
+	return name == CNFHashDigest || newHash(name) != nil
+}
+
+// Digester computes digests of all bytes written to it. It is meant
+// as target of an io.TeeReader, such that the digests are computed
+// from the same byte stream which is parsed and no file has to be
+// read twice. Every digest is computed in a separate goroutine.
+type Digester struct {
+	digests []*digest
+	closed  bool
+}
+
+// digest consumes the chunks written to a Digester
+type digest struct {
+	name   string
+	chunks chan []byte
+	sum    string
+	err    error
+	done   chan struct{}
+}
+
+// chunkReader reads the chunks of a digest, used for cnfhash
+type chunkReader struct {
+	chunks chan []byte
+	chunk  []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		chunk, ok := <-r.chunks
+		if !ok {
+			return 0, io.EOF
+		}
+		r.chunk = chunk
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+// NewDigester returns a Digester computing the digests named;
+// unknown names are ignored
+func NewDigester(names []string) *Digester {
+	d := new(Digester)
+	for _, name := range names {
+		dg := &digest{name: name, chunks: make(chan []byte, digestBacklog), done: make(chan struct{})}
+		if name == CNFHashDigest {
+			go func() {
+				defer close(dg.done)
+				dg.sum, dg.err = cnfhash.HashDIMACS(&chunkReader{chunks: dg.chunks}, cnfhash.Config{})
+				// consume remaining chunks, so writes do not block
+				for range dg.chunks {
+				}
+			}()
+		} else if h := newHash(name); h != nil {
+			go func() {
+				defer close(dg.done)
+				for chunk := range dg.chunks {
+					h.Write(chunk)
+				}
+				dg.sum = hex.EncodeToString(h.Sum(nil))
+			}()
+		} else {
+			continue
+		}
+		d.digests = append(d.digests, dg)
+	}
+	return d
+}
+
+// Write considers the bytes in p. It never fails; if a digest fails,
+// its error is returned by Sums. Bytes written after calling Sums are
+// ignored.
+func (d *Digester) Write(p []byte) (int, error) {
+	if d.closed || len(d.digests) == 0 || len(p) == 0 {
+		return len(p), nil
+	}
+	// p is reused by the caller, the copy is shared by all digests
+	chunk := make([]byte, len(p))
+	copy(chunk, p)
+	for _, dg := range d.digests {
+		dg.chunks <- chunk
+	}
+	return len(p), nil
+}
+
+// Sums returns the hex-encoded digests of all bytes written so far
+// by name. It also releases the goroutines of the Digester.
+func (d *Digester) Sums() (map[string]string, error) {
+	if !d.closed {
+		for _, dg := range d.digests {
+			close(dg.chunks)
+		}
+		d.closed = true
+	}
+
+	sums := make(map[string]string, len(d.digests))
+	var err error
+	for _, dg := range d.digests {
+		<-dg.done
+		if dg.err != nil && err == nil {
+			err = fmt.Errorf("%s: %s", dg.name, dg.err.Error())
+		}
+		sums[dg.name] = dg.sum
+	}
+	return sums, err
+}
@@ -0,0 +1,35 @@
+package stats
+
+// RankGF2 returns the rank of a matrix over GF(2) determined by
+// Gaussian elimination. Each row is a bitset of columns with bit c%64
+// of word c/64 representing column c. rows is modified.
+func RankGF2(rows [][]uint64, columns int) int {
+	rank := 0
+	for col := 0; col < columns && rank < len(rows); col++ {
+		w := col / 64
+		b := uint64(1) << uint(col%64)
+
+		pivot := -1
+		for r := rank; r < len(rows); r++ {
+			if rows[r][w]&b != 0 {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		rows[rank], rows[pivot] = rows[pivot], rows[rank]
+
+		// eliminate column below pivot; words before w are zero
+		for r := rank + 1; r < len(rows); r++ {
+			if rows[r][w]&b != 0 {
+				for i := w; i < len(rows[r]); i++ {
+					rows[r][i] ^= rows[rank][i]
+				}
+			}
+		}
+		rank += 1
+	}
+	return rank
+}
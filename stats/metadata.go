@@ -1,16 +1,11 @@
 package stats
 
 import (
-	"crypto/md5"
-	"crypto/sha1"
-	"encoding/hex"
-	"io"
-	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/prokls/cnf-analysis-go/input"
 	"github.com/prokls/cnf-analysis-go/output"
-	"github.com/prokls/cnf-hash-go/cnfhash"
 )
 
 func getTimestamp() string {
@@ -25,59 +20,47 @@ func getTimestamp() string {
 	return str
 }
 
-func computeHashes(path string) (string, string, error) {
-	sha1 := sha1.New()
-	md5 := md5.New()
-
-	f, err := os.Open(path)
-	if err != nil {
-		return "", "", err
-	}
-	defer f.Close()
-
-	buf := make([]byte, os.Getpagesize())
-	for {
-		n, err := f.Read(buf)
-		if err != nil && err != io.EOF {
-			return "", "", err
-		}
-		sha1.Write(buf[:n])
-		md5.Write(buf[:n])
-
-		if err == io.EOF {
-			break
-		}
-	}
-
-	return hex.EncodeToString(sha1.Sum(nil)), hex.EncodeToString(md5.Sum(nil)), nil
-}
-
-func Metadata(s *output.Stats, path string, conf *FeatureConfig) error {
+// Metadata stores metadata of the CNF read from path in s.
+// content digests the (decompressed) CNF content, compressed digests
+// the raw input if compression is not input.NoCompression. Both must
+// have considered the entire input. path "-" refers to stdin. For
+// archive members, path is the archive and conf.Member the member.
+func Metadata(s *output.Stats, path string, compression string, content, compressed *Digester, conf *FeatureConfig) error {
 	var err error
 
-	// cnfhash
-	fd, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	s.CNFHash, err = cnfhash.HashDIMACS(fd, cnfhash.Config{})
-	if err != nil {
-		return err
-	}
-	fd.Close()
-
 	// filename
-	if conf.FullPath {
+	if conf.FullPath || path == "-" {
 		s.Filename = path
 	} else {
 		s.Filename = filepath.Base(path)
 	}
+	if conf.Member != "" {
+		s.Filename += input.MemberSeparator + conf.Member
+	}
 
-	// hashes
-	s.SHA1Sum, s.MD5Sum, err = computeHashes(path)
+	// hashes of the (decompressed) CNF content
+	sums, err := content.Sums()
 	if err != nil {
 		return err
 	}
+	s.MD5Sum = sums[MD5Digest]
+	s.SHA1Sum = sums[SHA1Digest]
+	s.SHA256Sum = sums[SHA256Digest]
+	s.BLAKE2bSum = sums[BLAKE2bDigest]
+	s.CNFHash = sums[CNFHashDigest]
+
+	// hashes of the compressed input
+	if compression != input.NoCompression {
+		s.Compression = compression
+		sums, err = compressed.Sums()
+		if err != nil {
+			return err
+		}
+		s.CompressedMD5Sum = sums[MD5Digest]
+		s.CompressedSHA1Sum = sums[SHA1Digest]
+		s.CompressedSHA256Sum = sums[SHA256Digest]
+		s.CompressedBLAKE2bSum = sums[BLAKE2bDigest]
+	}
 
 	// timestamp
 	s.Timestamp = getTimestamp()
@@ -0,0 +1,203 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Welford accumulates the mean value, population standard deviation,
+// smallest and largest value of a stream of values. It uses
+// Welford's online algorithm and hence requires constant memory.
+type Welford struct {
+	count    uint64
+	mean     float64
+	m2       float64
+	smallest float64
+	largest  float64
+}
+
+// Add considers one more value
+func (w *Welford) Add(x float64) {
+	w.count += 1
+	if w.count == 1 || x < w.smallest {
+		w.smallest = x
+	}
+	if w.count == 1 || x > w.largest {
+		w.largest = x
+	}
+	delta := x - w.mean
+	w.mean += delta / float64(w.count)
+	w.m2 += delta * (x - w.mean)
+}
+
+// Count returns the number of values considered
+func (w *Welford) Count() uint64 {
+	return w.count
+}
+
+// Mean returns the mean value of all values considered so far
+func (w *Welford) Mean() float64 {
+	return w.mean
+}
+
+// Stdev returns the population standard deviation of all values
+// considered so far
+func (w *Welford) Stdev() float64 {
+	if w.count == 0 {
+		return 0.0
+	}
+	return math.Sqrt(w.m2 / float64(w.count))
+}
+
+// Smallest returns the smallest value considered so far
+func (w *Welford) Smallest() float64 {
+	return w.smallest
+}
+
+// Largest returns the largest value considered so far
+func (w *Welford) Largest() float64 {
+	return w.largest
+}
+
+// P2Quantile estimates a quantile of a stream of values in constant
+// memory. It implements the P² algorithm by Jain and Chlamtac (1985)
+// which maintains five markers whose heights approximate the minimum,
+// the p/2-, p-, (1+p)/2-quantile and the maximum. The estimate is
+// exact for up to five values only.
+type P2Quantile struct {
+	p     float64
+	count int
+	q     [5]float64 // marker heights
+	n     [5]float64 // actual marker positions
+	np    [5]float64 // desired marker positions
+	dn    [5]float64 // increments of desired marker positions
+}
+
+// NewP2Quantile returns an estimator for the p-quantile, 0 < p < 1
+func NewP2Quantile(p float64) *P2Quantile {
+	e := new(P2Quantile)
+	e.p = p
+	e.dn = [5]float64{0, p / 2, p, (1 + p) / 2, 1}
+	return e
+}
+
+// Add considers one more value
+func (e *P2Quantile) Add(x float64) {
+	if e.count < 5 {
+		e.q[e.count] = x
+		e.count += 1
+		if e.count == 5 {
+			sort.Float64s(e.q[:])
+			e.n = [5]float64{1, 2, 3, 4, 5}
+			e.np = [5]float64{1, 1 + 2*e.p, 1 + 4*e.p, 3 + 2*e.p, 5}
+		}
+		return
+	}
+	e.count += 1
+
+	// determine cell k with q[k] <= x < q[k+1]
+	k := 0
+	if x < e.q[0] {
+		e.q[0] = x
+	} else if x >= e.q[4] {
+		e.q[4] = x
+		k = 3
+	} else {
+		for x >= e.q[k+1] {
+			k += 1
+		}
+	}
+
+	for i := k + 1; i < 5; i++ {
+		e.n[i] += 1
+	}
+	for i := 0; i < 5; i++ {
+		e.np[i] += e.dn[i]
+	}
+
+	// adjust heights of the three inner markers
+	for i := 1; i < 4; i++ {
+		d := e.np[i] - e.n[i]
+		if (d >= 1 && e.n[i+1]-e.n[i] > 1) || (d <= -1 && e.n[i-1]-e.n[i] < -1) {
+			s := math.Copysign(1, d)
+			q := e.parabolic(i, s)
+			if e.q[i-1] < q && q < e.q[i+1] {
+				e.q[i] = q
+			} else {
+				e.q[i] = e.linear(i, s)
+			}
+			e.n[i] += s
+		}
+	}
+}
+
+func (e *P2Quantile) parabolic(i int, d float64) float64 {
+	return e.q[i] + d/(e.n[i+1]-e.n[i-1])*
+		((e.n[i]-e.n[i-1]+d)*(e.q[i+1]-e.q[i])/(e.n[i+1]-e.n[i])+
+			(e.n[i+1]-e.n[i]-d)*(e.q[i]-e.q[i-1])/(e.n[i]-e.n[i-1]))
+}
+
+func (e *P2Quantile) linear(i int, d float64) float64 {
+	j := i + int(d)
+	return e.q[i] + d*(e.q[j]-e.q[i])/(e.n[j]-e.n[i])
+}
+
+// Value returns the current estimate of the quantile.
+// If less than five values have been considered, the quantile is
+// interpolated linearly between the closest ranks, which yields
+// the usual median for p = 0.5.
+func (e *P2Quantile) Value() float64 {
+	if e.count == 0 {
+		return 0.0
+	}
+	if e.count >= 5 {
+		return e.q[2]
+	}
+
+	x := make([]float64, e.count)
+	copy(x, e.q[:e.count])
+	sort.Float64s(x)
+
+	pos := e.p * float64(e.count-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return x[lo] + (pos-float64(lo))*(x[hi]-x[lo])
+}
+
+// IntMedian determines the median of a stream of small non-negative
+// integers (like clause lengths) exactly. It counts the occurences of
+// every value, hence its memory is linear in the largest value.
+type IntMedian struct {
+	counts []uint64
+	count  uint64
+}
+
+// Add considers one more value, which must be a non-negative integer
+func (m *IntMedian) Add(x float64) {
+	i := int(x)
+	for len(m.counts) <= i {
+		m.counts = append(m.counts, 0)
+	}
+	m.counts[i] += 1
+	m.count += 1
+}
+
+// nth returns the k-th smallest value considered, counting from 0
+func (m *IntMedian) nth(k uint64) float64 {
+	for i, c := range m.counts {
+		if k < c {
+			return float64(i)
+		}
+		k -= c
+	}
+	return 0.0
+}
+
+// Value returns the median of all values considered so far, which is
+// the mean of both middle values for an even number of values
+func (m *IntMedian) Value() float64 {
+	if m.count == 0 {
+		return 0.0
+	}
+	return (m.nth((m.count-1)/2) + m.nth(m.count/2)) / 2
+}
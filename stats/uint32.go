@@ -3,6 +3,7 @@ package stats
 import (
 	"fmt"
 	"math"
+	"slices"
 )
 
 // MeanUint32 computes the mean value of uint32 elements.
@@ -60,3 +61,64 @@ func StdevUint32(x []uint32, mean float64) (float64, error) {
 	factor := math.Sqrt(1.0 / float64(len(x)))
 	return factor * math.Sqrt(tmp), nil
 }
+
+// LargestUint32 computes the maximum value the given elements.
+func LargestUint32(x []uint32) (uint32, error) {
+	// special cases
+	if len(x) == 0 {
+		return 0, fmt.Errorf("Cannot determine largest value of 0 elements")
+	}
+	if len(x) == 1 {
+		return x[0], nil
+	}
+
+	largest := x[0]
+	for _, val := range x {
+		if val > largest {
+			largest = val
+		}
+	}
+
+	return largest, nil
+}
+
+// SmallestUint32 computes the minimum value the given elements.
+func SmallestUint32(x []uint32) (uint32, error) {
+	// special cases
+	if len(x) == 0 {
+		return 0, fmt.Errorf("Cannot determine smallest value of 0 elements")
+	}
+	if len(x) == 1 {
+		return x[0], nil
+	}
+
+	smallest := x[0]
+	for _, val := range x {
+		if val < smallest {
+			smallest = val
+		}
+	}
+
+	return smallest, nil
+}
+
+// MedianUint32 computes the median value of the given elements.
+// It copies the parameter, sorts it and determines the median.
+func MedianUint32(y []uint32) (float64, error) {
+	if len(y) == 0 {
+		return 0.0, fmt.Errorf("Cannot determine median of 0 elements")
+	}
+	x := make([]uint32, len(y))
+	copy(x, y)
+
+	// sorting in O(n * log n)
+	slices.Sort(x)
+
+	// element selection
+	mid := int(len(x) / 2)
+	if len(x)%2 == 1 {
+		return float64(x[mid]), nil
+	} else {
+		return (float64(x[mid]) + float64(x[mid-1])) / 2.0, nil
+	}
+}
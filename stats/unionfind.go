@@ -2,6 +2,7 @@ package stats
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/prokls/cnf-analysis-go/output"
 	"github.com/prokls/cnf-analysis-go/sat"
@@ -11,26 +12,40 @@ import (
 
 type UFType uint32
 
-type unionFind struct {
-	elements []UFType
+// UFWideType is used instead of UFType if there are more elements
+// than UFType can represent
+type UFWideType uint64
+
+type ufElement interface {
+	~uint32 | ~uint64
+}
+
+type unionFind[E ufElement] struct {
+	elements []E
 }
 
-func newUnionFind(size int) *unionFind {
-	uf := new(unionFind)
-	uf.elements = make([]UFType, size)
+func newUnionFind[E ufElement](size int) *unionFind[E] {
+	uf := new(unionFind[E])
+	uf.elements = make([]E, size)
 
 	for i := 0; i < size; i++ {
-		uf.elements[i] = UFType(i)
+		uf.elements[i] = E(i)
 	}
 
 	return uf
 }
 
-func (uf *unionFind) Find(e UFType) (UFType, error) {
-	if len(uf.elements) <= int(e) {
+// grow extends the union find data structure to size elements
+// where each new element is its own representative
+func (uf *unionFind[E]) grow(size int) {
+	for i := len(uf.elements); i < size; i++ {
+		uf.elements = append(uf.elements, E(i))
+	}
+}
+
+func (uf *unionFind[E]) Find(e E) (E, error) {
+	if uint64(len(uf.elements)) <= uint64(e) {
 		return 0, fmt.Errorf("%d exceeds %d", e, len(uf.elements))
-	} else if e < 0 {
-		return 0, fmt.Errorf("union find element must be non-negative, is %d", e)
 	}
 
 	c := e
@@ -44,7 +59,7 @@ func (uf *unionFind) Find(e UFType) (UFType, error) {
 	}
 }
 
-func (uf *unionFind) Union(a, b UFType) error {
+func (uf *unionFind[E]) Union(a, b E) error {
 	reprA, err := uf.Find(a)
 	if err != nil {
 		return err
@@ -57,8 +72,8 @@ func (uf *unionFind) Union(a, b UFType) error {
 	return nil
 }
 
-func (uf *unionFind) Count() (int, error) {
-	reps := make(map[UFType]bool)
+func (uf *unionFind[E]) Count() (int, error) {
+	reps := make(map[E]bool)
 
 	for _, elem := range uf.elements {
 		rep, err := uf.Find(elem)
@@ -76,28 +91,36 @@ func (uf *unionFind) Count() (int, error) {
 
 // literal and variable components
 
-func posEquiv(lit sat.Lit) sat.Lit {
+func posEquiv[L sat.Literal](lit L) int {
 	if lit < 0 {
-		return -2*lit - 2
+		return -2*int(lit) - 2
 	} else {
-		return 2*lit - 1
+		return 2*int(lit) - 1
+	}
+}
+
+// EvaluateComponents determines the connected literal and variable
+// components of cnf. The union find elements are only widened if
+// the formula has more literals than UFType can represent.
+func EvaluateComponents[L sat.Literal](cnf *sat.Formula[L], feat *output.Features, fconf *FeatureConfig) error {
+	if 2*cnf.VarCount() <= math.MaxUint32 {
+		return evaluateComponents[L, UFType](cnf, feat)
 	}
+	return evaluateComponents[L, UFWideType](cnf, feat)
 }
 
-func EvaluateComponents(cnf *sat.CNF, feat *output.Features, fconf *FeatureConfig) error {
-	cc := newUnionFind(2 * cnf.NbVars)
+func evaluateComponents[L sat.Literal, E ufElement](cnf *sat.Formula[L], feat *output.Features) error {
+	nbvars := cnf.VarCount()
+	cc := newUnionFind[E](2 * nbvars)
 
 	// literal components
-	var ref UFType
-	readRef := true
-	for _, lit := range cnf.Lits {
-		if lit == 0 {
-			readRef = true
-		} else if readRef {
-			ref = UFType(posEquiv(lit))
-			readRef = false
-		} else {
-			err := cc.Union(ref, UFType(posEquiv(lit)))
+	for _, clause := range cnf.Clauses() {
+		if len(clause) == 0 {
+			continue
+		}
+		ref := E(posEquiv(clause[0]))
+		for _, lit := range clause[1:] {
+			err := cc.Union(ref, E(posEquiv(lit)))
 			if err != nil {
 				return err
 			}
@@ -109,9 +132,9 @@ func EvaluateComponents(cnf *sat.CNF, feat *output.Features, fconf *FeatureConfi
 		return err
 	}
 	// variable components
-	for vari := sat.Lit(1); vari <= sat.Lit(cnf.NbVars); vari++ {
-		pos := UFType(posEquiv(vari))
-		neg := UFType(posEquiv(-vari))
+	for vari := 1; vari <= nbvars; vari++ {
+		pos := E(posEquiv(L(vari)))
+		neg := E(posEquiv(L(-vari)))
 		err = cc.Union(pos, neg)
 		if err != nil {
 			return err
@@ -122,7 +145,141 @@ func EvaluateComponents(cnf *sat.CNF, feat *output.Features, fconf *FeatureConfi
 	if err != nil {
 		return err
 	}
-	feat.ConnectedLiteralComponentsCount = uint16(connLitComps)
-	feat.ConnectedVariableComponentsCount = uint16(connVarComps)
+	feat.ConnectedLiteralComponentsCount = uint64(connLitComps)
+	feat.ConnectedVariableComponentsCount = uint64(connVarComps)
+	return nil
+}
+
+// ComponentCounter determines connected literal and variable
+// components of a CNF whose clauses are passed one at a time.
+// It grows with the largest variable seen. Every union of two
+// components decrements the number of components, hence they can
+// be evaluated at any time.
+type ComponentCounter struct {
+	// literal l at index posEquiv(l), variable v at index v-1
+	literals  *unionFind[UFType]
+	variables *unionFind[UFType]
+	// number of unions of two different components
+	literalUnions  int
+	variableUnions int
+}
+
+func NewComponentCounter(nbvars int) *ComponentCounter {
+	cc := new(ComponentCounter)
+	cc.literals = newUnionFind[UFType](2 * nbvars)
+	cc.variables = newUnionFind[UFType](nbvars)
+	return cc
+}
+
+// union unifies the components of a and b in uf
+// and returns whether they were different
+func union(uf *unionFind[UFType], a, b UFType) (bool, error) {
+	reprA, err := uf.Find(a)
+	if err != nil {
+		return false, err
+	}
+	reprB, err := uf.Find(b)
+	if err != nil {
+		return false, err
+	}
+	if reprA == reprB {
+		return false, nil
+	}
+	uf.elements[reprA] = reprB
+	return true, nil
+}
+
+// AddClause unifies the components of all literals of clause
+func (cc *ComponentCounter) AddClause(clause []sat.Lit) error {
+	for i, lit := range clause {
+		v := lit
+		if v < 0 {
+			v = -v
+		}
+		cc.literals.grow(2 * int(v))
+		cc.variables.grow(int(v))
+		if i == 0 {
+			continue
+		}
+		merged, err := union(cc.literals, UFType(posEquiv(clause[0])), UFType(posEquiv(lit)))
+		if err != nil {
+			return err
+		}
+		if merged {
+			cc.literalUnions += 1
+		}
+		ref := clause[0]
+		if ref < 0 {
+			ref = -ref
+		}
+		merged, err = union(cc.variables, UFType(ref-1), UFType(v-1))
+		if err != nil {
+			return err
+		}
+		if merged {
+			cc.variableUnions += 1
+		}
+	}
+	return nil
+}
+
+// Evaluate stores the number of components in feat. Variables up to
+// nbvars are considered even if they never occured in a clause.
+// More clauses may be added afterwards.
+func (cc *ComponentCounter) Evaluate(nbvars int, feat *output.Features) error {
+	cc.literals.grow(2 * nbvars)
+	cc.variables.grow(nbvars)
+
+	feat.ConnectedLiteralComponentsCount = uint64(len(cc.literals.elements) - cc.literalUnions)
+	feat.ConnectedVariableComponentsCount = uint64(len(cc.variables.elements) - cc.variableUnions)
+	return nil
+}
+
+// EvaluatePBComponents determines the number of connected components
+// of the variable-constraint graph of pbf, i.e. the bipartite graph
+// connecting every constraint with the variables occuring in it.
+// Unused variables and constraints without terms are components
+// of their own.
+func EvaluatePBComponents(pbf *sat.PBF, feat *output.PBFeatures) error {
+	// variables may exceed the number of variables of the header
+	nbvars := pbf.NbVars
+	for _, c := range pbf.Constraints {
+		for _, t := range c.Terms {
+			if v := int(t.Lit); v > nbvars {
+				nbvars = v
+			} else if -v > nbvars {
+				nbvars = -v
+			}
+		}
+	}
+	uf := newUnionFind[UFType](nbvars)
+
+	empty := 0
+	for _, c := range pbf.Constraints {
+		if len(c.Terms) == 0 {
+			empty += 1
+			continue
+		}
+		ref := c.Terms[0].Lit
+		if ref < 0 {
+			ref = -ref
+		}
+		for _, t := range c.Terms[1:] {
+			v := t.Lit
+			if v < 0 {
+				v = -v
+			}
+			err := uf.Union(UFType(ref-1), UFType(v-1))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	count, err := uf.Count()
+	if err != nil {
+		return err
+	}
+	feat.ConnectedComponentsCount = uint64(count + empty)
 	return nil
 }
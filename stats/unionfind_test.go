@@ -0,0 +1,27 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/prokls/cnf-analysis-go/output"
+	"github.com/prokls/cnf-analysis-go/sat"
+)
+
+func TestEvaluatePBComponents(t *testing.T) {
+	// variables 3 and 4 exceed the number of variables of the header
+	pbf := sat.NewPBF()
+	pbf.NbVars = 2
+	pbf.Constraints = append(pbf.Constraints,
+		sat.PBConstraint{Terms: []sat.PBTerm{{Coef: 1, Lit: 1}, {Coef: 1, Lit: -3}}},
+		sat.PBConstraint{Terms: []sat.PBTerm{{Coef: 2, Lit: 4}, {Coef: 1, Lit: 3}}},
+		sat.PBConstraint{},
+	)
+	feat := output.NewPBFeatures()
+	if err := EvaluatePBComponents(pbf, feat); err != nil {
+		t.Fatal(err)
+	}
+	// {1, 3, 4}, the unused variable 2 and the empty constraint
+	if feat.ConnectedComponentsCount != 3 {
+		t.Errorf("got %d components", feat.ConnectedComponentsCount)
+	}
+}